@@ -3,20 +3,26 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
-	"ted/internal/colors"
-	"ted/internal/config"
-	"ted/internal/gemini"
-	"ted/internal/history"
-	"ted/internal/ui"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/shell"
+	"github.com/jadenpxrk/ted/internal/ui"
+	"github.com/jadenpxrk/ted/pkg/ted"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
+var (
+	agentPrint   bool
+	agentJSON    bool
+	agentNoTools bool
+)
+
 var agentCmd = &cobra.Command{
 	Use:   "agent [query]",
 	Short: "Generate a command from natural language",
@@ -25,7 +31,19 @@ var agentCmd = &cobra.Command{
 Example:
   ted agent how to make a python virtual environment
   ted agent list all files in current directory
-  ted agent compress a folder into a zip file`,
+  ted agent compress a folder into a zip file
+  cat error.log | ted agent restart the service that is failing
+
+Before answering, the model may call read-only tools to inspect the system:
+check whether a program is installed and look up its man page. Listing a
+directory, reading the start of a file and running git status can be turned
+on with the tools setting in config.yaml; they only see the working
+directory. Each call is shown as it happens. Turn tools off for one request
+with --no-tools.
+
+Use --print to write only the command to stdout without confirming or
+running it, e.g. for shell integration (see 'ted shell-init'). Use --json
+to write the full response with model, latency and token usage instead.`,
 	RunE: runAgent,
 }
 
@@ -36,31 +54,70 @@ func runAgent(cmd *cobra.Command, args []string) error {
 
 	query := strings.Join(args, " ")
 
+	// Machine-readable output goes to stdout; errors alone go to stderr.
+	machine := agentPrint || agentJSON
+	if machine {
+		cmd.SilenceUsage = true
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
-	if cfg.GeminiAPIKey == "" {
-		return fmt.Errorf("gemini API key not configured. Run 'ted settings' to set it up")
+	piped, err := readPipedStdin()
+	if err != nil {
+		return err
 	}
 
-	client, err := gemini.NewClient(cfg.GeminiAPIKey, cfg.Model, cfg.Temperature)
+	sh := resolveShell(cfg)
+	opts := []ted.Option{ted.WithShell(sh.Name)}
+	if agentNoTools {
+		opts = append(opts, ted.WithTools())
+	}
+	client, err := newTedClient(cfg, opts...)
 	if err != nil {
-		return fmt.Errorf("error creating Gemini client: %w", err)
+		return err
 	}
-	defer client.Close()
 
-	fmt.Printf("%s\n", colors.ThinkingStyle.Render("Thinking..."))
+	if !machine {
+		fmt.Printf("%s\n", colors.ThinkingStyle.Render("Thinking..."))
+	}
+
+	callOpts := append(callOptions(), ted.WithInput(piped))
+	if !machine {
+		callOpts = append(callOpts, ted.OnToolCall(func(call ted.ToolCall) {
+			fmt.Printf("%s\n", colors.TimeStyle.Render("→ "+call.String()))
+		}))
+	}
 
-	ctx := context.Background()
-	response, err := client.GenerateAgentCommand(ctx, query)
+	suggestion, err := client.Suggest(context.Background(), query, callOpts...)
 	if err != nil {
-		return fmt.Errorf("error generating command: %w", err)
+		return err
 	}
 
-	confirmModel := ui.NewConfirmModel(response.Command, response.Explanation)
-	p := tea.NewProgram(confirmModel)
+	if agentJSON {
+		return writeJSON(struct {
+			*ted.Suggestion
+			responseMeta
+		}{suggestion, metaOf(suggestion.Meta)})
+	}
+	if agentPrint {
+		fmt.Println(suggestion.Command)
+		return nil
+	}
+
+	if suggestion.Meta.Cached {
+		printCacheHit(suggestion.Meta.CachedAt)
+	}
+	return confirmAndExecute(cfg, sh, "agent", query, suggestion.Command, suggestion.Explanation, tokensOf(client.Model(), gemini.Usage(suggestion.Meta.Usage)))
+}
+
+// confirmAndExecute shows the confirmation UI for command and, if accepted,
+// runs it and records it in history under mode.
+func confirmAndExecute(cfg *config.Config, sh *shell.Executor, mode, query, command, explanation string, tokens history.Tokens) error {
+	confirmModel := ui.NewConfirmModel(command, explanation)
+	p := tea.NewProgram(confirmModel, tea.WithInput(terminalInput()))
 	finalModel, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("error running UI: %w", err)
@@ -72,45 +129,33 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if confirm.ShouldExecute() {
-		if err := executeCommand(response.Command); err != nil {
-			return err
-		}
+	if !confirm.ShouldExecute() {
+		return nil
+	}
 
-		if err := saveToHistory(query, response); err != nil {
+	run, execErr := executeCommand(cfg, sh, mode, query, command)
+	if run != nil {
+		if err := saveToHistory(mode, query, command, explanation, run, tokens); err != nil {
 			fmt.Printf("Warning: Failed to save to history: %v\n", err)
 		}
 	}
-
-	return nil
-}
-
-func executeCommand(command string) error {
-	fmt.Printf("%s\n", colors.RunningStyle.Render(fmt.Sprintf("Running `%s`", command)))
-
-	cmd := exec.Command("sh", "-c", command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("command failed: %w", err)
-	}
-
-	return nil
+	return execErr
 }
 
-func saveToHistory(query string, response *gemini.AgentResponse) error {
+func saveToHistory(mode, query, command, explanation string, run *history.Run, tokens history.Tokens) error {
 	hist, err := history.Load()
 	if err != nil {
 		return err
 	}
 	defer hist.Close()
 
-	selected := response.Command
-	return hist.AddEntry("agent", query, response.Explanation, &selected)
+	return hist.AddEntry(mode, query, explanation, &command, run, tokens)
 }
 
 func init() {
+	agentCmd.Flags().BoolVar(&agentPrint, "print", false, "Print the command without confirming or running it")
+	agentCmd.Flags().BoolVar(&agentJSON, "json", false, "Print the response as JSON without confirming or running it")
+	addCacheFlags(agentCmd)
+	agentCmd.Flags().BoolVar(&agentNoTools, "no-tools", false, "Don't let the model call tools to inspect the system")
 	rootCmd.AddCommand(agentCmd)
 }
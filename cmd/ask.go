@@ -4,28 +4,41 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 
-	"ted/internal/colors"
-	"ted/internal/config"
-	"ted/internal/gemini"
-	"ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/pkg/ted"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	askCount int
+	askPrint bool
+	askJSON  bool
+)
+
 var askCmd = &cobra.Command{
 	Use:   "ask [question]",
 	Short: "Get multiple command suggestions for a question",
-	Long: `Get top 3 command suggestions for a question.
+	Long: `Get command suggestions for a question.
+
+Each suggestion is labelled with its risk level, whether it modifies state,
+and any tools it needs. The number of suggestions defaults to 3 and can be
+changed with --count or the ask_count setting in config.yaml.
 
 Example:
   ted ask how to make a python virtual environment
   ted ask how to find large files
-  ted ask how to check disk usage`,
+  ted ask -n 5 how to check disk usage
+  kubectl get pods | ted ask which pods are crash looping
+
+Use --print to write the commands to stdout, one per line, without the
+selection prompt. Use --json to write the full response with model,
+latency and token usage instead.`,
 	RunE: runAsk,
 }
 
@@ -36,36 +49,70 @@ func runAsk(cmd *cobra.Command, args []string) error {
 
 	question := strings.Join(args, " ")
 
+	// Machine-readable output goes to stdout; errors alone go to stderr.
+	machine := askPrint || askJSON
+	if machine {
+		cmd.SilenceUsage = true
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
-	if cfg.GeminiAPIKey == "" {
-		return fmt.Errorf("gemini API key not configured. Run 'ted settings' to set it up")
+	count := cfg.AskCount
+	if cmd.Flags().Changed("count") {
+		count = askCount
+	}
+	if count < 1 || count > config.MaxAskCount {
+		return fmt.Errorf("number of suggestions must be between 1 and %d", config.MaxAskCount)
 	}
 
-	client, err := gemini.NewClient(cfg.GeminiAPIKey, cfg.Model, cfg.Temperature)
+	piped, err := readPipedStdin()
 	if err != nil {
-		return fmt.Errorf("error creating Gemini client: %w", err)
+		return err
 	}
-	defer client.Close()
 
-	fmt.Printf("%s\n\n", colors.ThinkingStyle.Render("Thinking..."))
+	sh := resolveShell(cfg)
+	client, err := newTedClient(cfg, ted.WithShell(sh.Name))
+	if err != nil {
+		return err
+	}
+
+	if !machine {
+		fmt.Printf("%s\n\n", colors.ThinkingStyle.Render("Thinking..."))
+	}
 
-	ctx := context.Background()
-	response, err := client.GenerateAskCommands(ctx, question)
+	callOpts := append(callOptions(), ted.WithInput(piped), ted.WithCount(count))
+	response, err := client.Ask(context.Background(), question, callOpts...)
 	if err != nil {
-		return fmt.Errorf("error generating commands: %w", err)
+		return err
 	}
 
+	if askJSON {
+		return writeJSON(struct {
+			*ted.Suggestions
+			responseMeta
+		}{response, metaOf(response.Meta)})
+	}
+	if askPrint {
+		for _, option := range response.Commands {
+			fmt.Println(option.Command)
+		}
+		return nil
+	}
+
+	if response.Meta.Cached {
+		printCacheHit(response.Meta.CachedAt)
+	}
 	for i, option := range response.Commands {
 		coloredCommand := colors.CommandStyle.Render(fmt.Sprintf("`%s`", option.Command))
 		fmt.Printf("%d. %s - %s\n", i+1, coloredCommand, option.Description)
+		fmt.Printf("   %s\n", renderBadges(option))
 	}
 
-	fmt.Print("\nSelect an option (1-3) or press Enter to exit: ")
-	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Printf("\nSelect an option (1-%d) or press Enter to exit: ", len(response.Commands))
+	scanner := bufio.NewScanner(terminalInput())
 	if !scanner.Scan() {
 		return nil
 	}
@@ -82,17 +129,10 @@ func runAsk(cmd *cobra.Command, args []string) error {
 
 	selectedCommand := response.Commands[choice-1].Command
 
-	fmt.Printf("%s\n", colors.RunningStyle.Render(fmt.Sprintf("Running `%s`", selectedCommand)))
-
-	execCmd := exec.Command("sh", "-c", selectedCommand)
-	execCmd.Stdout = os.Stdout
-	execCmd.Stderr = os.Stderr
-	execCmd.Stdin = os.Stdin
-
-	execCmdErr := execCmd.Run()
+	run, execCmdErr := executeCommand(cfg, sh, "ask", question, selectedCommand)
 
-	hist, err := history.Load()
-	if err == nil {
+	// As in agent mode, only a command that ran is recorded.
+	if run != nil {
 		responseText := ""
 		for i, option := range response.Commands {
 			if i > 0 {
@@ -100,19 +140,39 @@ func runAsk(cmd *cobra.Command, args []string) error {
 			}
 			responseText += fmt.Sprintf("%d. `%s` - %s", i+1, option.Command, option.Description)
 		}
-		if err := hist.AddEntry("ask", question, responseText, &selectedCommand); err != nil {
+		if err := saveToHistory("ask", question, selectedCommand, responseText, run, tokensOf(client.Model(), gemini.Usage(response.Meta.Usage))); err != nil {
 			fmt.Printf("Warning: Failed to save to history: %v\n", err)
 		}
-		hist.Close()
 	}
 
-	if execCmdErr != nil {
-		return fmt.Errorf("command failed: %w", execCmdErr)
+	return execCmdErr
+}
+
+// renderBadges formats the ranking metadata of a command option.
+func renderBadges(option ted.Candidate) string {
+	level := option.RiskLevel
+	if level == "" {
+		level = "unknown"
+	}
+	badges := []string{colors.RiskStyle(option.RiskLevel).Render(fmt.Sprintf("[%s risk]", level))}
+
+	if option.ModifiesState {
+		badges = append(badges, colors.RiskMediumStyle.Render("[modifies state]"))
+	} else {
+		badges = append(badges, colors.BadgeStyle.Render("[read-only]"))
+	}
+
+	if len(option.RequiredTools) > 0 {
+		badges = append(badges, colors.BadgeStyle.Render(fmt.Sprintf("[needs: %s]", strings.Join(option.RequiredTools, ", "))))
 	}
 
-	return nil
+	return strings.Join(badges, " ")
 }
 
 func init() {
+	askCmd.Flags().IntVarP(&askCount, "count", "n", config.DefaultAskCount, "Number of suggestions to request")
+	askCmd.Flags().BoolVar(&askPrint, "print", false, "Print the commands without the selection prompt")
+	askCmd.Flags().BoolVar(&askJSON, "json", false, "Print the response as JSON without the selection prompt")
+	addCacheFlags(askCmd)
 	rootCmd.AddCommand(askCmd)
 }
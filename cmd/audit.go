@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/audit"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check the audit log of executed commands",
+	Long: `Every command ted runs, from agent, ask, fix, plan or chat, is appended to
+an audit log: ~/.ted/audit.log, or the path set as audit_log in config.yaml.
+Unlike the history, it is never pruned or cleared by ted.
+
+Each line is a JSON record of the user, host, working directory, shell, the
+query, the command that ran, whether it was edited from the model's
+suggestion, its exit code and when it started and finished. Every record
+holds the SHA-256 hash of the one before it, so changing, reordering or
+removing records breaks the chain. If the log can't be opened, ted refuses to
+run commands.
+
+'ted audit verify' checks the chain and prints the hash of the last record.
+Keep a copy of that hash elsewhere to also detect records removed from the
+end of the log.`,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:          "verify",
+	Short:        "Check that the audit log hasn't been tampered with",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAuditVerify,
+}
+
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	path := auditLogPath(cfg)
+	summary, err := audit.Verify(path)
+	if err != nil {
+		var chainErr *audit.ChainError
+		if errors.As(err, &chainErr) {
+			return fmt.Errorf("audit log %s is broken at %w", path, err)
+		}
+		return err
+	}
+
+	if summary.Records == 0 {
+		fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("Audit log %s is empty.", path)))
+		return nil
+	}
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("✓ Audit log intact: %d records", summary.Records)))
+	fmt.Printf("%s %s\n", colors.SettingsLabelStyle.Render("File:"), colors.SettingsValueStyle.Render(path))
+	fmt.Printf("%s %s\n", colors.SettingsLabelStyle.Render("Head:"), colors.SettingsValueStyle.Render(summary.Head))
+	return nil
+}
+
+// auditLogPath returns the configured audit log, or ~/.ted/audit.log.
+func auditLogPath(cfg *config.Config) string {
+	path := cfg.AuditLog
+	if path == "" {
+		return filepath.Join(config.GetConfigPath(), audit.FileName)
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// openAuditLog opens the audit log before a command runs, so that nothing
+// runs that can't be recorded.
+func openAuditLog(cfg *config.Config) (*audit.Log, error) {
+	log, err := audit.Open(auditLogPath(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("refusing to run commands: %w", err)
+	}
+	return log, nil
+}
+
+// recordExecution appends record to log, warning on stderr if it can't.
+func recordExecution(log *audit.Log, record audit.Record) {
+	if _, err := log.Append(record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// exitStatus returns the exit code for the error returned by running a
+// command, and a description if it didn't exit normally.
+func exitStatus(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if code := exitErr.ExitCode(); code >= 0 {
+			return code, ""
+		}
+	}
+	return -1, err.Error()
+}
+
+func init() {
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
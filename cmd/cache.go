@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	noCache      bool
+	refreshCache bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the response cache",
+	Long: `Inspect or clear the cache of agent, ask and explain responses.
+
+Responses are cached in ~/.ted/history.db, keyed on the normalized query,
+the model and its temperature, your OS and shell, the prompt templates and
+any piped input. Entries expire after cache_ttl (7 days by default); set it
+to "0" in config.yaml to disable the cache.
+
+Pass --refresh to agent, ask or explain to ignore a cached answer and update
+it, or --no-cache to bypass the cache entirely.`,
+	RunE: runCacheStats,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache statistics",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheStats,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached responses",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheClear,
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	stats, err := hist.GetCacheStats()
+	if err != nil {
+		return err
+	}
+
+	ttl := cfg.CacheTTL.String()
+	if cfg.CacheTTL == 0 {
+		ttl = "disabled"
+	}
+
+	fmt.Printf("%s\n", colors.TitleStyle.Render("Response Cache"))
+	printCacheStat("Entries", fmt.Sprintf("%d (%d expired)", stats.Entries, stats.Expired))
+	printCacheStat("Hits", fmt.Sprintf("%d", stats.Hits))
+	printCacheStat("Size", fmt.Sprintf("%.1f KB", float64(stats.Bytes)/1024))
+	printCacheStat("TTL", ttl)
+	if stats.Entries > 0 {
+		printCacheStat("Oldest", stats.Oldest.Format("2006-01-02 15:04"))
+		printCacheStat("Newest", stats.Newest.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func printCacheStat(label, value string) {
+	fmt.Printf("%s %s\n", colors.SettingsLabelStyle.Render(fmt.Sprintf("%-8s", label+":")), colors.SettingsValueStyle.Render(value))
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	hist, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	if err := hist.ClearCache(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.SuccessStyle.Render("Response cache cleared."))
+	return nil
+}
+
+// addCacheFlags adds --no-cache and --refresh to a command that caches its
+// responses.
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Don't read or write the response cache")
+	cmd.Flags().BoolVar(&refreshCache, "refresh", false, "Ignore a cached response and update it")
+}
+
+// printCacheHit tells the user that an answer came from the cache.
+func printCacheHit(saved time.Time) {
+	fmt.Printf("%s\n", colors.TimeStyle.Render(fmt.Sprintf("⚡ From cache (saved %s). Use --refresh to ask again.", formatAge(time.Since(saved)))))
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%d min ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d h ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d days ago", int(age.Hours()/24))
+	}
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/redact"
+	"github.com/jadenpxrk/ted/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+const maxSessionTitle = 60
+
+var (
+	chatResume uint64
+	chatList   bool
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Start a multi-turn conversation",
+	Long: `Start a conversation that remembers earlier messages, so follow-ups like
+"now only for .go files" work.
+
+Suggested commands are numbered. Type /run to run the latest one or /run N
+to run an earlier one; its output is sent back into the conversation.
+Sessions are saved and can be resumed later.
+
+Example:
+  ted chat
+  ted chat --list
+  ted chat --resume 3`,
+	RunE: runChat,
+}
+
+func runChat(cmd *cobra.Command, args []string) error {
+	if chatList {
+		return listChatSessions()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	session := &history.Session{}
+	if chatResume != 0 {
+		session, err = loadChatSession(chatResume)
+		if err != nil {
+			return err
+		}
+	}
+
+	sh := resolveShell(cfg)
+	data := prompts.NewData("", sh.Name)
+	data.Mode = "chat"
+
+	client, err := newClient(cfg, "chat", data)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	system, err := systemInstruction(cfg, data)
+	if err != nil {
+		return err
+	}
+	chatPrompt, err := prompts.Render(prompts.Chat, cfg.Prompts, data)
+	if err != nil {
+		return err
+	}
+	client.SetSystemInstruction(system + "\n\n" + chatPrompt)
+
+	var turns []gemini.ChatTurn
+	var entries []ui.ChatEntry
+	for _, message := range session.Messages {
+		turns = append(turns, gemini.ChatTurn{Role: message.Role, Text: message.Text, Command: message.Command})
+		entries = append(entries, ui.ChatEntry{Role: message.Role, Text: message.Text, Command: message.Command})
+	}
+	chat := client.StartChat(turns)
+
+	// The audit log records a command with the message that led to it.
+	var lastMessage string
+	handlers := ui.ChatHandlers{
+		Send: func(text string) (ui.ChatEntry, error) {
+			lastMessage = text
+			response, err := chat.Send(context.Background(), text)
+			if err != nil {
+				return ui.ChatEntry{}, err
+			}
+
+			if session.Title == "" {
+				session.Title = truncate(text, maxSessionTitle)
+			}
+			session.Messages = session.Messages[:0]
+			for _, turn := range chat.History() {
+				session.Messages = append(session.Messages, history.Message{Role: turn.Role, Text: turn.Text, Command: turn.Command})
+			}
+			// A failed save shouldn't interrupt the conversation; the
+			// session is saved again after the next reply.
+			_ = saveChatSession(session)
+
+			return ui.ChatEntry{Role: ui.RoleModel, Text: response.Message, Command: response.Command}, nil
+		},
+		Run: func(command string) (string, int) {
+			// The output goes back to the model and into the saved session.
+			output, code := captureCommand(cfg, sh, "chat", lastMessage, command)
+			return redact.String(output), code
+		},
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s\n", colors.TitleStyle.Render("Ted Chat"))
+	} else {
+		fmt.Printf("%s\n", colors.TitleStyle.Render(fmt.Sprintf("Ted Chat: %s", session.Title)))
+	}
+
+	p := tea.NewProgram(ui.NewChatModel(handlers, entries), tea.WithInput(terminalInput()))
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error running UI: %w", err)
+	}
+
+	if session.ID != 0 {
+		fmt.Printf("%s\n", colors.SettingsInfoStyle.Render(fmt.Sprintf("Session saved. Resume with: ted chat --resume %d", session.ID)))
+	}
+	return nil
+}
+
+func listChatSessions() error {
+	hist, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	sessions, err := hist.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.TitleStyle.Render("Chat Sessions"))
+	if len(sessions) == 0 {
+		fmt.Printf("%s\n", colors.QueryStyle.Render("No saved sessions. Start one with 'ted chat'."))
+		return nil
+	}
+
+	for _, session := range sessions {
+		fmt.Printf("%s %s\n", colors.EntryStyle.Render(fmt.Sprintf("%d.", session.ID)), colors.QueryStyle.Render(session.Title))
+		fmt.Printf("   %s\n", colors.TimeStyle.Render(fmt.Sprintf("%s, %d messages", session.Updated.Format("2006-01-02 15:04"), len(session.Messages))))
+	}
+	return nil
+}
+
+func loadChatSession(id uint64) (*history.Session, error) {
+	hist, err := history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	return hist.GetSession(id)
+}
+
+// saveChatSession opens the history database only for the write so other ted
+// processes aren't locked out while the chat is open.
+func saveChatSession(session *history.Session) error {
+	hist, err := history.Load()
+	if err != nil {
+		return err
+	}
+	defer hist.Close()
+
+	return hist.SaveSession(session)
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+func init() {
+	chatCmd.Flags().Uint64Var(&chatResume, "resume", 0, "Resume the chat session with this ID")
+	chatCmd.Flags().BoolVar(&chatList, "list", false, "List saved chat sessions")
+	rootCmd.AddCommand(chatCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/provider"
+	"github.com/jadenpxrk/ted/pkg/ted"
+)
+
+// newClient creates a Gemini client for cfg with the system instruction and
+// few-shot examples rendered for data, for the modes that aren't part of the
+// public API. The tokens of every request are logged under mode.
+func newClient(cfg *config.Config, mode string, data prompts.Data) (*gemini.Client, error) {
+	warnMonthlyLimit(cfg)
+	return provider.NewClient(cfg, mode, data)
+}
+
+// newTedClient creates a client for agent, ask and explain, honoring
+// --no-cache.
+func newTedClient(cfg *config.Config, opts ...ted.Option) (*ted.Client, error) {
+	warnMonthlyLimit(cfg)
+	return ted.New(append([]ted.Option{ted.WithCache(!noCache)}, opts...)...)
+}
+
+// callOptions returns the options for a request, honoring --refresh.
+func callOptions() []ted.CallOption {
+	if refreshCache {
+		return []ted.CallOption{ted.RefreshCache()}
+	}
+	return nil
+}
+
+// systemInstruction renders the system template with the stored few-shot
+// examples.
+func systemInstruction(cfg *config.Config, data prompts.Data) (string, error) {
+	return provider.SystemInstruction(cfg, data)
+}
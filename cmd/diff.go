@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+)
+
+const (
+	diffContext = 3
+
+	// maxDiffCells bounds the size of the table used to compare files; larger
+	// files are shown as a full replacement.
+	maxDiffCells = 4_000_000
+)
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// renderDiff returns a colored line diff from oldText to newText, showing
+// changed lines with a few lines of context around them.
+func renderDiff(oldText, newText string) string {
+	lines := diffLines(splitDiffLines(oldText), splitDiffLines(newText))
+
+	// Mark the lines to show: every change plus its context.
+	show := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.op == ' ' {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(lines)-1, i+diffContext); j++ {
+			show[j] = true
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	oldLine := 1
+	for i, line := range lines {
+		if !show[i] {
+			skipped = true
+			oldLine++
+			continue
+		}
+		if skipped || i == 0 {
+			b.WriteString(colors.TimeStyle.Render(fmt.Sprintf("@@ line %d @@", oldLine)))
+			b.WriteString("\n")
+			skipped = false
+		}
+		if line.op != '+' {
+			oldLine++
+		}
+		switch line.op {
+		case '-':
+			b.WriteString(colors.DiffRemoveStyle.Render("-" + line.text))
+		case '+':
+			b.WriteString(colors.DiffAddStyle.Render("+" + line.text))
+		default:
+			b.WriteString(colors.BadgeStyle.Render(" " + line.text))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// diffLines compares a and b using their longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	var lines []diffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, text := range a {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{'+', text})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+func splitDiffLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
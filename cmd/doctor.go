@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration problems",
+	Long: `Check your ted installation for common problems.
+
+This validates config.yaml and any prompt templates, checks the permissions
+of ~/.ted, verifies the history database, and sends a minimal request to the
+configured model. Each failed check is printed with a suggested fix. The
+command exits with a non-zero status when any check fails.`,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+// doctorCheck is the result of a single diagnostic. A skipped check couldn't
+// run because of a problem another check already reports, so it isn't
+// counted as a failure.
+type doctorCheck struct {
+	name    string
+	problem string
+	fix     string
+	skipped string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	fmt.Printf("%s\n\n", colors.TitleStyle.Render("Ted Doctor"))
+
+	var checks []doctorCheck
+	checks = append(checks, checkConfigFile()...)
+	checks = append(checks, checkPermissions()...)
+	checks = append(checks, checkPromptFiles()...)
+	checks = append(checks, checkHistoryDB())
+	checks = append(checks, checkProvider())
+
+	failures := 0
+	for _, check := range checks {
+		if check.skipped != "" {
+			fmt.Printf("%s %s: %s\n", colors.SettingsInfoStyle.Render("-"), check.name, check.skipped)
+			continue
+		}
+		if check.problem == "" {
+			fmt.Printf("%s %s\n", colors.SuccessStyle.Render("✓"), check.name)
+			continue
+		}
+		failures++
+		fmt.Printf("%s %s: %s\n", colors.ErrorStyle.Render("✗"), check.name, check.problem)
+		if check.fix != "" {
+			fmt.Printf("  %s %s\n", colors.SettingsInfoStyle.Render("Fix:"), check.fix)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d check(s) failed", failures)
+	}
+
+	fmt.Printf("\n%s\n", colors.SuccessStyle.Render("Everything looks good!"))
+	return nil
+}
+
+func checkConfigFile() []doctorCheck {
+	issues, err := config.Validate()
+	if err != nil {
+		return []doctorCheck{{name: "config.yaml", problem: err.Error()}}
+	}
+	if len(issues) == 0 {
+		return []doctorCheck{{name: "config.yaml"}}
+	}
+
+	var checks []doctorCheck
+	for _, issue := range issues {
+		name := "config.yaml"
+		if issue.Key != "" {
+			name = fmt.Sprintf("config.yaml: %s", issue.Key)
+		}
+		checks = append(checks, doctorCheck{name: name, problem: issue.Problem, fix: issue.Fix})
+	}
+	return checks
+}
+
+func checkPermissions() []doctorCheck {
+	dir := config.GetConfigPath()
+	check := doctorCheck{name: fmt.Sprintf("%s permissions", dir)}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		check.problem = err.Error()
+		check.fix = "Run 'ted settings' to create the config directory"
+		return []doctorCheck{check}
+	}
+	if !info.IsDir() {
+		check.problem = "not a directory"
+		check.fix = fmt.Sprintf("Move %s out of the way and run 'ted settings'", dir)
+		return []doctorCheck{check}
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.problem = "directory is not writable"
+		check.fix = fmt.Sprintf("Run 'chmod u+rwx %s'", dir)
+		return []doctorCheck{check}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	checks := []doctorCheck{check}
+
+	configFile := config.GetConfigFile()
+	if info, err := os.Stat(configFile); err == nil && info.Mode().Perm()&0077 != 0 {
+		checks = append(checks, doctorCheck{
+			name:    fmt.Sprintf("%s permissions", configFile),
+			problem: fmt.Sprintf("file mode %04o lets other users read your API key", info.Mode().Perm()),
+			fix:     fmt.Sprintf("Run 'chmod 600 %s'", configFile),
+		})
+	}
+
+	return checks
+}
+
+func checkPromptFiles() []doctorCheck {
+	var checks []doctorCheck
+	for _, name := range prompts.Names {
+		path, err := prompts.GetTemplateFile(name)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		check := doctorCheck{name: fmt.Sprintf("prompts/%s.tmpl", name)}
+		if err := prompts.Check(string(data)); err != nil {
+			check.problem = fmt.Sprintf("invalid template: %v", err)
+			check.fix = fmt.Sprintf("Run 'ted prompt edit %s' to fix it or 'ted prompt reset %s' to restore the default", name, name)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func checkHistoryDB() doctorCheck {
+	dbPath, err := history.GetHistoryPath()
+	if err != nil {
+		return doctorCheck{name: "history.db", problem: err.Error()}
+	}
+
+	check := doctorCheck{name: filepath.Base(dbPath)}
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return check
+	}
+
+	if err := history.Verify(); err != nil {
+		check.problem = err.Error()
+		if errors.Is(err, history.ErrLocked) {
+			check.fix = "Close any other running ted processes and try again"
+		} else {
+			check.fix = fmt.Sprintf("Move %s aside; a new history will be created on the next run", dbPath)
+		}
+	}
+	return check
+}
+
+func checkProvider() doctorCheck {
+	// Read rather than Load, so that diagnosing doesn't create a missing
+	// config file; the config.yaml checks report it.
+	cfg, err := config.Read()
+	if errors.Is(err, config.ErrNoConfigFile) {
+		return doctorCheck{name: "provider", skipped: "skipped, config file does not exist"}
+	}
+	if err != nil {
+		return doctorCheck{name: "provider", problem: err.Error(), fix: "Fix the config file problems above"}
+	}
+
+	check := doctorCheck{name: fmt.Sprintf("model %s", cfg.Model)}
+	if cfg.GeminiAPIKey == "" {
+		// The config.yaml checks already report the missing key.
+		check.skipped = "skipped, API key is not set"
+		return check
+	}
+
+	client, err := gemini.NewClient(cfg.GeminiAPIKey, cfg.Model, cfg.Temperature)
+	if err != nil {
+		check.problem = err.Error()
+		return check
+	}
+	defer client.Close()
+	// Report rate limiting straight away instead of waiting it out.
+	client.SetMaxRetries(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := client.Probe(ctx); err != nil {
+		check.problem = err.Error()
+		switch {
+		case errors.Is(err, gemini.ErrInvalidAPIKey):
+			check.fix = "Run 'ted settings' and enter a valid Gemini API key"
+		case errors.Is(err, gemini.ErrRateLimited):
+			check.fix = "Wait for the limit to reset, or pick another model with 'ted settings'"
+		default:
+			check.fix = "Check your API key and run 'ted models' to see which models are available"
+		}
+	}
+	return check
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/examples"
+	"github.com/jadenpxrk/ted/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: "Manage few-shot examples sent with every request",
+	Long: `Manage the few-shot examples included in every agent and ask request.
+
+Each example pairs a query with the command that answered it, showing the
+model the kind of answer you expect. Examples are stored in
+~/.ted/examples.json.
+
+Example:
+  ted examples add "show listening ports" "ss -tlnp"
+  ted examples seed
+  ted examples remove 2`,
+	RunE: runExamplesList,
+}
+
+var examplesAddCmd = &cobra.Command{
+	Use:   "add [query] [command]",
+	Short: "Add a few-shot example",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runExamplesAdd,
+}
+
+var examplesRemoveCmd = &cobra.Command{
+	Use:   "remove [number]",
+	Short: "Remove a few-shot example",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExamplesRemove,
+}
+
+var examplesSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Add examples from agent and ask commands you ran successfully",
+	Args:  cobra.NoArgs,
+	RunE:  runExamplesSeed,
+}
+
+var examplesClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all few-shot examples",
+	Args:  cobra.NoArgs,
+	RunE:  runExamplesClear,
+}
+
+func runExamplesList(cmd *cobra.Command, args []string) error {
+	list, err := examples.Load()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.TitleStyle.Render("Few-shot Examples"))
+	if len(list) == 0 {
+		fmt.Printf("%s\n", colors.QueryStyle.Render("No examples yet. Try 'ted examples seed' or 'ted examples add [query] [command]'."))
+		return nil
+	}
+
+	for i, example := range list {
+		fmt.Printf("%s %s\n", colors.EntryStyle.Render(fmt.Sprintf("%d.", i+1)), colors.QueryStyle.Render(example.Query))
+		fmt.Printf("   %s\n", colors.CommandStyle.Render(fmt.Sprintf("`%s`", example.Command)))
+	}
+	return nil
+}
+
+func runExamplesAdd(cmd *cobra.Command, args []string) error {
+	added, err := examples.Add(examples.Example{Query: args[0], Command: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if added == 0 {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render("⚠️  Example already exists or is empty"))
+		return nil
+	}
+	fmt.Printf("%s\n", colors.SuccessStyle.Render("✓ Example added"))
+	return nil
+}
+
+func runExamplesRemove(cmd *cobra.Command, args []string) error {
+	num, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid example number '%s'", args[0])
+	}
+
+	if err := examples.Remove(num - 1); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("✓ Example %d removed", num)))
+	return nil
+}
+
+func runExamplesSeed(cmd *cobra.Command, args []string) error {
+	hist, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	entries, err := hist.GetEntries()
+	if err != nil {
+		return fmt.Errorf("error retrieving history entries: %w", err)
+	}
+
+	var seeded []examples.Example
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		// Only agent and ask entries pair a request with the command that
+		// answered it, and a command that failed isn't a good example.
+		if entry.Selected == nil || (entry.Command != "agent" && entry.Command != "ask") {
+			continue
+		}
+		if entry.Run != nil && entry.Run.ExitCode != 0 {
+			continue
+		}
+		seeded = append(seeded, examples.Example{Query: entry.Query, Command: *entry.Selected})
+	}
+
+	added, err := examples.Add(seeded...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("✓ Added %d example(s) from history", added)))
+	return nil
+}
+
+func runExamplesClear(cmd *cobra.Command, args []string) error {
+	if err := examples.Clear(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.SuccessStyle.Render("✓ All examples deleted"))
+	return nil
+}
+
+func init() {
+	examplesCmd.AddCommand(examplesAddCmd)
+	examplesCmd.AddCommand(examplesRemoveCmd)
+	examplesCmd.AddCommand(examplesSeedCmd)
+	examplesCmd.AddCommand(examplesClearCmd)
+	rootCmd.AddCommand(examplesCmd)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/audit"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/shell"
+)
+
+const (
+	captureTimeout   = 30 * time.Second
+	maxCaptureOutput = 4096
+)
+
+// resolveShell returns the shell that runs suggested commands, warning on
+// stderr when the configured shell is missing.
+func resolveShell(cfg *config.Config) *shell.Executor {
+	sh, err := shell.Resolve(cfg.Shell, cfg.ShellRC)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return sh
+}
+
+// executeCommand runs command in the terminal with the configured timeout,
+// output capture and resource limits, and records it in the audit log under
+// mode and the query it answers. It returns how the command ended for the
+// history, even if it failed.
+func executeCommand(cfg *config.Config, sh *shell.Executor, mode, query, command string) (*history.Run, error) {
+	log, err := openAuditLog(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer log.Close()
+
+	fmt.Printf("%s\n", colors.RunningStyle.Render(fmt.Sprintf("Running `%s`", command)))
+
+	started := time.Now()
+	result, err := sh.Run(command, runOptions(cfg))
+	if result == nil {
+		// The command didn't start.
+		result = &shell.Result{Started: started, Finished: time.Now()}
+	}
+
+	exitCode, problem := exitStatus(err)
+	recordExecution(log, audit.Record{
+		Started:  result.Started,
+		Finished: result.Finished,
+		Shell:    sh.Name,
+		Mode:     mode,
+		Query:    query,
+		Command:  command,
+		ExitCode: exitCode,
+		Error:    problem,
+	})
+
+	run := &history.Run{
+		ExitCode:  exitCode,
+		Duration:  result.Finished.Sub(result.Started),
+		TimedOut:  result.TimedOut,
+		Output:    result.Output,
+		Truncated: result.Truncated,
+	}
+	if err != nil {
+		return run, fmt.Errorf("command failed: %w", err)
+	}
+	return run, nil
+}
+
+// runOptions returns how executeCommand runs commands: attached to the
+// terminal, with the timeout, capture and limits from cfg.
+func runOptions(cfg *config.Config) shell.RunOptions {
+	limits := shell.Limits{
+		CPU:      cfg.Limits.CPU,
+		Memory:   cfg.Limits.MemoryMB << 20,
+		FileSize: cfg.Limits.FileSizeMB << 20,
+	}
+	if !limits.IsZero() && !shell.LimitsSupported {
+		fmt.Fprintf(os.Stderr, "Warning: %v, running without them\n", shell.ErrLimitsUnsupported)
+		limits = shell.Limits{}
+	}
+
+	return shell.RunOptions{
+		Stdin:        terminalInput(),
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		Timeout:      cfg.ExecTimeout,
+		CaptureLimit: cfg.CaptureLimit,
+		Limits:       limits,
+	}
+}
+
+// captureCommand runs command without a terminal and returns the tail of its
+// combined output along with its exit status. It runs with the configured
+// limits and timeout, though never for longer than captureTimeout, and like
+// executeCommand records the command in the audit log.
+func captureCommand(cfg *config.Config, sh *shell.Executor, mode, query, command string) (string, int) {
+	log, err := openAuditLog(cfg)
+	if err != nil {
+		return err.Error(), 1
+	}
+	defer log.Close()
+
+	opts := runOptions(cfg)
+	opts.Stdin, opts.Stdout, opts.Stderr = nil, nil, nil
+	opts.CaptureLimit = maxCaptureOutput
+	if opts.Timeout == 0 || opts.Timeout > captureTimeout {
+		opts.Timeout = captureTimeout
+	}
+
+	started := time.Now()
+	result, err := sh.Run(command, opts)
+	if result == nil {
+		result = &shell.Result{Started: started, Finished: time.Now()}
+	}
+
+	code, problem := exitStatus(err)
+	recordExecution(log, audit.Record{
+		Started:  result.Started,
+		Finished: result.Finished,
+		Shell:    sh.Name,
+		Mode:     mode,
+		Query:    query,
+		Command:  command,
+		ExitCode: code,
+		Error:    problem,
+	})
+
+	output := result.Output
+	if problem != "" {
+		output += "\n" + problem
+	}
+
+	return strings.TrimSpace(output), code
+}
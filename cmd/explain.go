@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/pkg/ted"
+
+	"github.com/charmbracelet/lipgloss/tree"
+	"github.com/spf13/cobra"
+)
+
+var explainJSON bool
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [command]",
+	Short: "Explain what an existing shell command does",
+	Long: `Break down an existing shell command stage by stage and flag by flag.
+
+Risky parts, such as commands that delete data or run code from the network,
+are highlighted. Quote the command so your shell doesn't interpret it, or
+pass it on stdin.
+
+Example:
+  ted explain 'find . -name "*.log" -mtime +7 -exec rm {} +'
+  ted explain 'curl -fsSL https://example.com/install.sh | sudo bash'
+  pbpaste | ted explain`,
+	RunE: runExplain,
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	command := strings.Join(args, " ")
+	if len(args) == 0 || command == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading command from stdin: %w", err)
+		}
+		command = string(data)
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return fmt.Errorf("please provide a command. Example: ted explain 'tar -xzvf archive.tar.gz'")
+	}
+
+	if explainJSON {
+		cmd.SilenceUsage = true
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	sh := resolveShell(cfg)
+	client, err := newTedClient(cfg, ted.WithShell(sh.Name))
+	if err != nil {
+		return err
+	}
+
+	if !explainJSON {
+		fmt.Printf("%s\n\n", colors.ThinkingStyle.Render("Thinking..."))
+	}
+
+	response, err := client.Explain(context.Background(), command, callOptions()...)
+	if err != nil {
+		return err
+	}
+
+	if explainJSON {
+		return writeJSON(struct {
+			*ted.Explanation
+			responseMeta
+		}{response, metaOf(response.Meta)})
+	}
+
+	if response.Meta.Cached {
+		printCacheHit(response.Meta.CachedAt)
+	}
+	fmt.Println(renderExplanation(command, response))
+	return nil
+}
+
+// renderExplanation draws the breakdown as a tree with one branch per stage
+// and one leaf per argument.
+func renderExplanation(command string, response *ted.Explanation) string {
+	root := tree.Root(fmt.Sprintf("%s %s\n%s",
+		colors.CommandStyle.Render(command),
+		riskBadge(response.RiskLevel),
+		colors.EntryStyle.Render(response.Summary))).
+		EnumeratorStyle(colors.BadgeStyle.PaddingRight(1))
+
+	for _, stage := range response.Stages {
+		value := fmt.Sprintf("%s %s\n%s",
+			colors.RiskStyle(stage.RiskLevel).Render(stage.Command),
+			riskBadge(stage.RiskLevel),
+			colors.DetailBoxStyle.Render(stage.Explanation))
+		if stage.RiskReason != "" && stage.RiskLevel != ted.RiskLow {
+			value += "\n" + colors.RiskStyle(stage.RiskLevel).Render("⚠️  "+stage.RiskReason)
+		}
+
+		branch := tree.Root(value).EnumeratorStyle(colors.BadgeStyle.PaddingRight(1))
+		for _, arg := range stage.Arguments {
+			text := colors.CommandStyle.Render(arg.Text)
+			if arg.RiskLevel != ted.RiskLow {
+				text = colors.RiskStyle(arg.RiskLevel).Render(arg.Text)
+			}
+			branch.Child(fmt.Sprintf("%s  %s", text, colors.QueryStyle.Render(arg.Explanation)))
+		}
+		root.Child(branch)
+	}
+
+	return root.String()
+}
+
+func riskBadge(level string) string {
+	if level == "" {
+		level = "unknown"
+	}
+	return colors.RiskStyle(level).Render(fmt.Sprintf("[%s risk]", level))
+}
+
+func init() {
+	explainCmd.Flags().BoolVar(&explainJSON, "json", false, "Print the explanation as JSON")
+	addCacheFlags(explainCmd)
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixRerun  bool
+	fixStatus int
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [command]",
+	Short: "Repair the last failed shell command",
+	Long: `Suggest a corrected version of the last command that failed.
+
+The last command and its exit status are read from the shell integration
+(see 'ted shell-init'). Pass a command to fix that one instead. Use --rerun
+to run the command again and send its output to the model; this executes
+the command, so only use it for commands that are safe to repeat.
+
+Example:
+  ted fix
+  ted fix --rerun
+  ted fix --status 127 'gti status'`,
+	RunE: runFix,
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	command := strings.Join(args, " ")
+	exitCode := fixStatus
+
+	if command == "" {
+		command = os.Getenv("TED_LAST_COMMAND")
+		if command == "" {
+			return fmt.Errorf("no previous command found. Set up shell integration with 'ted shell-init' or pass the command to fix")
+		}
+		if status, err := strconv.Atoi(os.Getenv("TED_LAST_STATUS")); err == nil {
+			exitCode = status
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	sh := resolveShell(cfg)
+	data := prompts.NewData(command, sh.Name)
+	data.Mode = "fix"
+	data.ExitCode = exitCode
+
+	if fixRerun {
+		fmt.Printf("%s\n", colors.RunningStyle.Render(fmt.Sprintf("Re-running `%s`", command)))
+		data.Output, data.ExitCode = captureCommand(cfg, sh, "fix", command, command)
+		// The output is sent to the model, so redact it like piped input.
+		data.Output = redact.String(data.Output)
+	}
+
+	if data.ExitCode == 0 {
+		fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("`%s` succeeded, nothing to fix.", command)))
+		return nil
+	}
+
+	fmt.Printf("%s %s %s\n", colors.QueryStyle.Render("Fixing"), colors.CommandStyle.Render(command),
+		colors.TimeStyle.Render(fmt.Sprintf("(exit status %d)", data.ExitCode)))
+
+	client, err := newClient(cfg, "fix", data)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	prompt, err := prompts.Render(prompts.Fix, cfg.Prompts, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", colors.ThinkingStyle.Render("Thinking..."))
+
+	ctx := context.Background()
+	response, err := client.GenerateAgentCommand(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("error generating command: %w", err)
+	}
+
+	return confirmAndExecute(cfg, sh, "fix", command, response.Command, response.Explanation, tokensOf(cfg.Model, response.Usage))
+}
+
+func init() {
+	fixCmd.Flags().BoolVar(&fixRerun, "rerun", false, "Run the command again to capture its output")
+	fixCmd.Flags().IntVar(&fixStatus, "status", 1, "Exit status of the command passed as an argument")
+	rootCmd.AddCommand(fixCmd)
+}
@@ -6,9 +6,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
-	"ted/internal/colors"
-	"ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/history"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -98,6 +99,12 @@ func runHistory(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s\n", colors.QueryStyle.Render(entry.Query))
 		fmt.Printf("%s\n", colors.TimeStyle.Render(entry.Timestamp.Format("2006-01-02 15:04")))
 
+		if len(entry.Steps) > 0 {
+			printPlanSteps(entry.Steps)
+			fmt.Println()
+			continue
+		}
+
 		// Selected
 		responseText := ""
 		if entry.Selected != nil {
@@ -157,6 +164,19 @@ func runHistory(cmd *cobra.Command, args []string) error {
 			fmt.Printf("%s\n", colors.CommandStyle.Render(fmt.Sprintf("[%s]", entry.Command)))
 			fmt.Printf("%s\n", colors.QueryStyle.Render(entry.Query))
 			fmt.Printf("%s\n", colors.TimeStyle.Render(entry.Timestamp.Format("2006-01-02 15:04")))
+			if entry.Tokens.Model != "" {
+				fmt.Printf("%s\n", colors.TimeStyle.Render(fmt.Sprintf("%s, %d tokens in, %d out",
+					entry.Tokens.Model, entry.Tokens.PromptTokens, entry.Tokens.ResponseTokens)))
+			}
+
+			if len(entry.Steps) > 0 {
+				fmt.Printf("%s\n", colors.EntryStyle.Render(entry.Response))
+				for i, step := range entry.Steps {
+					fmt.Printf("\n%s %s\n", colors.EntryStyle.Render(fmt.Sprintf("Step %d:", i+1)), colors.QueryStyle.Render(step.Explanation))
+					printPlanSteps(entry.Steps[i : i+1])
+				}
+				return nil
+			}
 
 			// Selected
 			responseText := ""
@@ -166,6 +186,15 @@ func runHistory(cmd *cobra.Command, args []string) error {
 				responseText = entry.Response
 			}
 			fmt.Printf("%s\n", colors.SelectedOptionStyle.Render(fmt.Sprintf("`%s`", responseText)))
+			if entry.Run != nil {
+				printRun(entry.Run)
+			}
+
+			if entry.Script != "" {
+				fmt.Printf("\n%s:\n%s\n",
+					colors.FullResponseStyle.Render("Script"),
+					colors.DetailBoxStyle.Render(strings.TrimRight(entry.Script, "\n")))
+			}
 
 			// Show full response if it's different from selected
 			if entry.Selected != nil && entry.Response != *entry.Selected {
@@ -186,6 +215,48 @@ func runHistory(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printRun prints how an entry's command ended and the output captured.
+func printRun(run *history.Run) {
+	outcome := colors.SuccessStyle.Render(fmt.Sprintf("✓ exit 0 in %s", run.Duration.Round(time.Millisecond)))
+	switch {
+	case run.TimedOut:
+		outcome = colors.ErrorStyle.Render(fmt.Sprintf("✗ timed out after %s", run.Duration.Round(time.Second)))
+	case run.ExitCode != 0:
+		outcome = colors.ErrorStyle.Render(fmt.Sprintf("✗ exit %d in %s", run.ExitCode, run.Duration.Round(time.Millisecond)))
+	}
+	fmt.Printf("%s\n", outcome)
+
+	if run.Output == "" {
+		return
+	}
+	label := "Output"
+	if run.Truncated {
+		label = "Output (end)"
+	}
+	fmt.Printf("\n%s:\n%s\n",
+		colors.FullResponseStyle.Render(label),
+		colors.DetailBoxStyle.Render(strings.TrimRight(run.Output, "\n")))
+}
+
+// printPlanSteps prints each step of a plan entry with how it ended.
+func printPlanSteps(steps []history.Step) {
+	for _, step := range steps {
+		var outcome string
+		switch step.Status {
+		case history.StepSucceeded:
+			outcome = colors.SuccessStyle.Render("✓")
+		case history.StepFailed:
+			outcome = colors.ErrorStyle.Render(fmt.Sprintf("✗ exit %d", step.ExitCode))
+		default:
+			outcome = colors.TimeStyle.Render(step.Status)
+		}
+		if step.Edited {
+			outcome += " " + colors.TimeStyle.Render("(edited)")
+		}
+		fmt.Printf("%s %s\n", colors.SelectedOptionStyle.Render(fmt.Sprintf("`%s`", step.Command)), outcome)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(historyCmd)
 }
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/mcp"
+	"github.com/jadenpxrk/ted/internal/redact"
+	"github.com/jadenpxrk/ted/pkg/ted"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultSearchLimit is how many entries search_history returns by default.
+const defaultSearchLimit = 5
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server over stdio",
+	Long: `Run a Model Context Protocol (MCP) server on stdin and stdout, so
+MCP-capable assistants can use ted's command generation and history with
+your configured model, prompt templates, examples and cache.
+
+Tools:
+  suggest_command    Generate one shell command for a task
+  suggest_commands   Generate several alternative commands
+  explain_command    Break down what a shell command does and how risky it is
+  search_history     Search ted's command history
+
+The server never runs commands. Register it with your assistant as a stdio
+server with the command 'ted mcp', for example:
+
+  {"mcpServers": {"ted": {"command": "ted", "args": ["mcp"]}}}`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runMCP,
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	shellName := resolveShell(cfg).Name
+	client, err := newTedClient(cfg, ted.WithShell(shellName))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return newMCPServer(client, shellName).Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// newMCPServer creates the MCP server with ted's tools. Commands are written
+// for shellName.
+func newMCPServer(client *ted.Client, shellName string) *mcp.Server {
+	server := mcp.NewServer("ted", Version)
+	server.SetInstructions("ted suggests and explains shell commands for the user's OS and shell. " +
+		"It never runs them; show suggested commands to the user before running anything.")
+
+	minCount, maxCount := 1, config.MaxAskCount
+	minLimit := 1
+
+	addMCPTool(server, mcp.Tool{
+		Name:        "suggest_command",
+		Description: fmt.Sprintf("Generate a single %s command that accomplishes a task described in natural language, with an explanation.", shellName),
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"query": {Type: "string", Description: "The task, e.g. 'find files larger than 100MB'"},
+				"input": {Type: "string", Description: "Optional text the command should work with, such as an error message or log excerpt"},
+			},
+			Required: []string{"query"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (*mcp.Result, error) {
+			var args struct {
+				Query string `json:"query"`
+				Input string `json:"input"`
+			}
+			if err := decodeToolArgs(raw, &args); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(args.Query) == "" {
+				return nil, fmt.Errorf("query is required")
+			}
+
+			response, err := client.Suggest(ctx, args.Query, ted.WithInput(args.Input))
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.Result{
+				Text:       fmt.Sprintf("%s\n\n%s", response.Command, response.Explanation),
+				Structured: response,
+			}, nil
+		},
+	})
+
+	addMCPTool(server, mcp.Tool{
+		Name:        "suggest_commands",
+		Description: fmt.Sprintf("Generate several alternative %s commands for a question, each with a description and risk level.", shellName),
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"query": {Type: "string", Description: "The question, e.g. 'how to find large files'"},
+				"input": {Type: "string", Description: "Optional text the commands should work with"},
+				"count": {Type: "integer", Description: "Number of suggestions", Minimum: &minCount, Maximum: &maxCount},
+			},
+			Required: []string{"query"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (*mcp.Result, error) {
+			var args struct {
+				Query string `json:"query"`
+				Input string `json:"input"`
+				Count int    `json:"count"`
+			}
+			if err := decodeToolArgs(raw, &args); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(args.Query) == "" {
+				return nil, fmt.Errorf("query is required")
+			}
+			opts := []ted.CallOption{ted.WithInput(args.Input)}
+			if args.Count != 0 {
+				opts = append(opts, ted.WithCount(args.Count))
+			}
+
+			response, err := client.Ask(ctx, args.Query, opts...)
+			if err != nil {
+				return nil, err
+			}
+
+			var text strings.Builder
+			for i, option := range response.Commands {
+				fmt.Fprintf(&text, "%d. %s\n   %s [%s risk]\n", i+1, option.Command, option.Description, option.RiskLevel)
+			}
+			return &mcp.Result{Text: strings.TrimRight(text.String(), "\n"), Structured: response}, nil
+		},
+	})
+
+	addMCPTool(server, mcp.Tool{
+		Name:        "explain_command",
+		Description: "Explain what a shell command does, stage by stage and flag by flag, with the risk of each part.",
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"command": {Type: "string", Description: "The command to explain"},
+			},
+			Required: []string{"command"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (*mcp.Result, error) {
+			var args struct {
+				Command string `json:"command"`
+			}
+			if err := decodeToolArgs(raw, &args); err != nil {
+				return nil, err
+			}
+			command := strings.TrimSpace(args.Command)
+			if command == "" {
+				return nil, fmt.Errorf("command is required")
+			}
+
+			response, err := client.Explain(ctx, command)
+			if err != nil {
+				return nil, err
+			}
+
+			var text strings.Builder
+			fmt.Fprintf(&text, "%s [%s risk]\n", response.Summary, response.RiskLevel)
+			for _, stage := range response.Stages {
+				fmt.Fprintf(&text, "\n%s [%s risk]\n  %s\n", stage.Command, stage.RiskLevel, stage.Explanation)
+				if stage.RiskReason != "" {
+					fmt.Fprintf(&text, "  Risk: %s\n", stage.RiskReason)
+				}
+				for _, arg := range stage.Arguments {
+					fmt.Fprintf(&text, "  %s: %s\n", arg.Text, arg.Explanation)
+				}
+			}
+			return &mcp.Result{Text: strings.TrimRight(text.String(), "\n"), Structured: response}, nil
+		},
+	})
+
+	addMCPTool(server, mcp.Tool{
+		Name:        "search_history",
+		Description: "Search the commands the user recently generated and ran with ted, most recent first.",
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"query": {Type: "string", Description: "Text to look for in the question or command; empty returns all entries"},
+				"limit": {Type: "integer", Description: "Maximum number of entries to return", Minimum: &minLimit},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (*mcp.Result, error) {
+			var args struct {
+				Query string `json:"query"`
+				Limit int    `json:"limit"`
+			}
+			if err := decodeToolArgs(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Limit <= 0 {
+				args.Limit = defaultSearchLimit
+			}
+
+			entries, err := searchHistory(ctx, client, args.Query, args.Limit)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) == 0 {
+				return &mcp.Result{Text: "No matching history entries.", Structured: map[string]any{"entries": entries}}, nil
+			}
+
+			var text strings.Builder
+			for _, entry := range entries {
+				fmt.Fprintf(&text, "[%s] %s %s\n", entry.Timestamp.Format("2006-01-02 15:04"), entry.Mode, entry.Query)
+				if entry.Selected != nil {
+					fmt.Fprintf(&text, "  %s\n", *entry.Selected)
+				}
+			}
+			return &mcp.Result{Text: strings.TrimRight(text.String(), "\n"), Structured: map[string]any{"entries": entries}}, nil
+		},
+	})
+
+	return server
+}
+
+// searchHistory returns up to limit matching history entries. Secrets are
+// redacted since the entries leave ted.
+func searchHistory(ctx context.Context, client *ted.Client, query string, limit int) ([]ted.HistoryEntry, error) {
+	entries, err := client.SearchHistory(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	redactHistory(entries)
+	return entries, nil
+}
+
+// redactHistory redacts secrets from history entries before they are sent
+// out of ted by the MCP and HTTP servers.
+func redactHistory(entries []ted.HistoryEntry) {
+	for i := range entries {
+		entry := &entries[i]
+		entry.Query = redact.String(entry.Query)
+		entry.Response = redact.String(entry.Response)
+		entry.Script = redact.String(entry.Script)
+		if entry.Selected != nil {
+			selected := redact.String(*entry.Selected)
+			entry.Selected = &selected
+		}
+		for j := range entry.Steps {
+			entry.Steps[j].Command = redact.String(entry.Steps[j].Command)
+		}
+		if entry.Run != nil {
+			entry.Run.Output = redact.String(entry.Run.Output)
+		}
+	}
+}
+
+// addMCPTool registers tool, redacting secrets from its errors since
+// provider errors can quote the request URL.
+func addMCPTool(server *mcp.Server, tool mcp.Tool) {
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, args json.RawMessage) (*mcp.Result, error) {
+		result, err := handler(ctx, args)
+		if err != nil {
+			return nil, errors.New(redact.String(err.Error()))
+		}
+		return result, nil
+	}
+	server.AddTool(tool)
+}
+
+// decodeToolArgs decodes the arguments of a tool call, rejecting unknown ones.
+func decodeToolArgs(raw json.RawMessage, v any) error {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var modelsRefresh bool
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List available AI models",
+	Long: `List the models available to your API key, with their context window
+and capabilities.
+
+The list is cached in ~/.ted/models.json for 24 hours. Use --refresh to
+fetch it again. When the API can't be reached a built-in list is shown.`,
+	RunE: runModels,
+}
+
+func runModels(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	list, source, err := models.List(context.Background(), cfg.GeminiAPIKey, modelsRefresh)
+	if err != nil {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  Could not fetch models: %v", err)))
+	}
+
+	fmt.Printf("%s\n", colors.TitleStyle.Render("Available Models"))
+	fmt.Printf("%s\n\n", colors.TimeStyle.Render(fmt.Sprintf("Source: %s", source)))
+
+	for _, model := range list {
+		fmt.Printf("%s", colors.CommandStyle.Render(model.Name))
+		if model.Name == cfg.Model {
+			fmt.Printf(" %s", colors.SettingsConfiguredStyle.Render("(current)"))
+		}
+		fmt.Println()
+		if model.DisplayName != "" {
+			fmt.Printf("  %s\n", colors.QueryStyle.Render(model.DisplayName))
+		}
+		fmt.Printf("  %s %s\n", colors.SettingsLabelStyle.Render("Context window:"),
+			colors.SettingsValueStyle.Render(fmt.Sprintf("%d input / %d output tokens", model.InputTokenLimit, model.OutputTokenLimit)))
+		fmt.Printf("  %s %s\n", colors.SettingsLabelStyle.Render("Capabilities:"),
+			colors.SettingsInfoStyle.Render(strings.Join(model.Capabilities, ", ")))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func init() {
+	modelsCmd.Flags().BoolVar(&modelsRefresh, "refresh", false, "Ignore the cached list and fetch models again")
+	rootCmd.AddCommand(modelsCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/pkg/ted"
+)
+
+// responseMeta describes the request that produced a response in --json output.
+type responseMeta struct {
+	Model     string       `json:"model"`
+	LatencyMS int64        `json:"latency_ms"`
+	Usage     gemini.Usage `json:"usage"`
+	Cached    bool         `json:"cached"`
+}
+
+func newResponseMeta(model string, latency time.Duration, usage gemini.Usage) responseMeta {
+	return responseMeta{
+		Model:     model,
+		LatencyMS: latency.Milliseconds(),
+		Usage:     usage,
+	}
+}
+
+// metaOf converts the metadata of a public API response.
+func metaOf(meta ted.Meta) responseMeta {
+	result := newResponseMeta(meta.Model, meta.Latency, gemini.Usage(meta.Usage))
+	result.Cached = meta.Cached
+	return result
+}
+
+// writeJSON writes v to stdout as indented JSON.
+func writeJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/audit"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var planJSON bool
+
+var planCmd = &cobra.Command{
+	Use:   "plan [task]",
+	Short: "Break a task into steps and run them one at a time",
+	Long: `Break a task that needs several commands into an ordered plan and walk
+through it step by step.
+
+For each step, press y to run it, e to edit the command first, s to skip it,
+or q to stop. Press a to run the rest of the plan: steps marked safe run
+without asking, and run-all pauses at any step that isn't. The plan stops at
+the first step that fails.
+
+Example:
+  ted plan set up a Go project with a Makefile and a git repo
+  ted plan install and configure nginx as a reverse proxy for port 3000
+
+Use --json to write the plan with model, latency and token usage to stdout
+without running it.`,
+	RunE: runPlan,
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("please provide a task. Example: ted plan set up a Go project with a Makefile")
+	}
+
+	query := strings.Join(args, " ")
+
+	if planJSON {
+		cmd.SilenceUsage = true
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	piped, err := readPipedStdin()
+	if err != nil {
+		return err
+	}
+
+	sh := resolveShell(cfg)
+	data := prompts.NewData(query, sh.Name)
+	data.Mode = "plan"
+	data.Input = piped
+
+	client, err := newClient(cfg, "plan", data)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	prompt, err := prompts.Render(prompts.Plan, cfg.Prompts, data)
+	if err != nil {
+		return err
+	}
+
+	if !planJSON {
+		fmt.Printf("%s\n", colors.ThinkingStyle.Render("Thinking..."))
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	response, err := client.GeneratePlan(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("error generating plan: %w", err)
+	}
+
+	if planJSON {
+		return writeJSON(struct {
+			*gemini.PlanResponse
+			responseMeta
+		}{response, newResponseMeta(cfg.Model, time.Since(start), response.Usage)})
+	}
+
+	fmt.Printf("\n%s\n", colors.TitleStyle.Render(response.Summary))
+	steps := make([]ui.PlanStep, len(response.Steps))
+	for i, step := range response.Steps {
+		steps[i] = ui.PlanStep{Command: step.Command, Explanation: step.Explanation, Safe: step.Safe}
+
+		badge := colors.RiskMediumStyle.Render("[asks]")
+		if step.Safe {
+			badge = colors.RiskLowStyle.Render("[safe]")
+		}
+		fmt.Printf("%s %s %s\n", colors.EntryStyle.Render(fmt.Sprintf("%d.", i+1)), colors.CommandStyle.Render(step.Command), badge)
+		fmt.Printf("   %s\n", colors.QueryStyle.Render(step.Explanation))
+	}
+	fmt.Println()
+
+	log, err := openAuditLog(cfg)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	runStep := func(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+		opts := runOptions(cfg)
+		opts.Stdin, opts.Stdout, opts.Stderr = stdin, stdout, stderr
+		// Plans keep no output, so don't take the terminal from the step.
+		opts.CaptureLimit = 0
+		_, err := sh.Run(command, opts)
+		return err
+	}
+
+	// Each step is recorded as soon as it finishes, so steps that ran are in
+	// the audit log even if ted doesn't get to the end of the plan.
+	stepDone := func(index int, step ui.PlanStep) {
+		auditStep(log, sh.Name, query, response.Steps[index].Command, step)
+	}
+
+	p := tea.NewProgram(ui.NewPlanModel(steps, runStep, stepDone), tea.WithInput(terminalInput()))
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("error running UI: %w", err)
+	}
+
+	plan := finalModel.(ui.PlanModel)
+	if !plan.Ran() {
+		return nil
+	}
+	if err := savePlanToHistory(query, response.Summary, plan.Steps(), tokensOf(cfg.Model, response.Usage)); err != nil {
+		fmt.Printf("Warning: Failed to save to history: %v\n", err)
+	}
+	return nil
+}
+
+// auditStep records a plan step that ran in the audit log, with the command
+// the model suggested if the user edited it.
+func auditStep(log *audit.Log, shellName, query, suggested string, step ui.PlanStep) {
+	record := audit.Record{
+		Started:  step.Started,
+		Finished: step.Finished,
+		Shell:    shellName,
+		Mode:     "plan",
+		Query:    query,
+		Command:  step.Command,
+		Edited:   step.Edited,
+	}
+	record.ExitCode, record.Error = exitStatus(step.Err)
+	if step.Edited {
+		record.Suggested = suggested
+	}
+	recordExecution(log, record)
+}
+
+func savePlanToHistory(query, summary string, steps []ui.PlanStep, tokens history.Tokens) error {
+	hist, err := history.Load()
+	if err != nil {
+		return err
+	}
+	defer hist.Close()
+
+	records := make([]history.Step, len(steps))
+	for i, step := range steps {
+		records[i] = history.Step{
+			Command:     step.Command,
+			Explanation: step.Explanation,
+			Edited:      step.Edited,
+			Status:      step.Status,
+			ExitCode:    step.ExitCode,
+		}
+	}
+	return hist.AddPlan(query, summary, records, tokens)
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&planJSON, "json", false, "Print the plan as JSON without running it")
+	rootCmd.AddCommand(planCmd)
+}
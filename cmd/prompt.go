@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Show or customize prompt templates",
+	Long: `Show or customize the prompt templates sent to the AI model.
+
+Templates use Go text/template syntax with these variables:
+  {{.Query}}       The user's query, question or task, or the command to explain or fix
+  {{.OS}}          Operating system (e.g. linux, darwin)
+  {{.Shell}}       Shell that runs the command (e.g. zsh, bash)
+  {{.Cwd}}         Current working directory
+  {{.NumOptions}}  Number of suggestions requested in ask mode
+  {{.Examples}}    Few-shot examples, each with .Query and .Command
+  {{.Input}}       Content piped into ted on stdin
+  {{.Tools}}       Names of the tools the model may call in agent mode
+  {{.Mode}}        Kind of request: agent, ask, explain, fix, chat, plan or script
+  {{.Language}}    Language of the script generated by 'ted script'
+  {{.ExitCode}}    Exit status of the command passed to 'ted fix'
+  {{.Output}}      Output of the command passed to 'ted fix', if captured
+
+Overrides are read from ~/.ted/prompts/<name>.tmpl, or from the prompts
+section of config.yaml, which takes precedence.
+
+Available templates:
+  system  System instruction sent with every request
+  agent   Prompt for 'ted agent'
+  ask     Prompt for 'ted ask'
+  explain Prompt for 'ted explain'
+  fix     Prompt for 'ted fix'
+  chat    Added to the system instruction in 'ted chat'
+  plan    Prompt for 'ted plan'
+  script  Prompt for 'ted script'`,
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:       "show [system|agent|ask|explain|fix|chat|plan|script]",
+	Short:     "Print the effective prompt template",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompts.Names,
+	RunE:      runPromptShow,
+}
+
+var promptEditCmd = &cobra.Command{
+	Use:       "edit [system|agent|ask|explain|fix|chat|plan|script]",
+	Short:     "Edit a prompt template in $EDITOR",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompts.Names,
+	RunE:      runPromptEdit,
+}
+
+var promptResetCmd = &cobra.Command{
+	Use:       "reset [system|agent|ask|explain|fix|chat|plan|script]",
+	Short:     "Restore the built-in prompt template",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompts.Names,
+	RunE:      runPromptReset,
+}
+
+func runPromptShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	text, source, err := prompts.Lookup(args[0], cfg.Prompts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n\n", colors.TitleStyle.Render(fmt.Sprintf("%s prompt", args[0])), colors.TimeStyle.Render(fmt.Sprintf("(%s)", source)))
+	fmt.Println(strings.TrimRight(text, "\n"))
+	return nil
+}
+
+func runPromptEdit(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	path, err := prompts.GetTemplateFile(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		text, err := prompts.Default(name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create prompts directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write prompt template: %w", err)
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	editCmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	if err := prompts.Check(string(data)); err != nil {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  Template has errors: %v", err)))
+		fmt.Printf("%s\n", colors.SettingsInfoStyle.Render(fmt.Sprintf("Run 'ted prompt edit %s' to fix it or 'ted prompt reset %s' to restore the default.", name, name)))
+		return nil
+	}
+
+	fmt.Printf("%s %s\n", colors.SuccessStyle.Render("✓ Prompt template saved to"), colors.SettingsValueStyle.Render(path))
+
+	cfg, err := config.Load()
+	if err == nil && cfg.Prompts[name] != "" {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  config.yaml also sets prompts.%s, which takes precedence over this file.", name)))
+	}
+	return nil
+}
+
+func runPromptReset(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if _, err := prompts.Default(name); err != nil {
+		return err
+	}
+
+	path, err := prompts.GetTemplateFile(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove prompt template: %w", err)
+	}
+
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("✓ %s prompt reset to the built-in default", name)))
+
+	cfg, err := config.Load()
+	if err == nil && cfg.Prompts[name] != "" {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  config.yaml still sets prompts.%s; remove it to use the default.", name)))
+	}
+	return nil
+}
+
+func init() {
+	promptCmd.AddCommand(promptShowCmd)
+	promptCmd.AddCommand(promptEditCmd)
+	promptCmd.AddCommand(promptResetCmd)
+	rootCmd.AddCommand(promptCmd)
+}
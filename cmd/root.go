@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"log/slog"
 	"os"
+	"path/filepath"
+
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/logging"
 
 	"github.com/spf13/cobra"
 )
 
 var Version = "v0.0.1"
 
+var (
+	verbose bool
+	debug   bool
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:     "ted",
@@ -16,18 +26,75 @@ var rootCmd = &cobra.Command{
 	Long: `Ted is the fastest way to get answers in the terminal.
 
 Available commands:
-  agent    - Generate a single command from natural language and optionally execute it
-  ask      - Get multiple command suggestions for a question  
-  history  - View your command history with an interactive interface
-  settings - Configure API keys and preferences
-  version  - Show version information
+  agent      - Generate a single command from natural language and optionally execute it
+  ask        - Get multiple command suggestions for a question  
+  audit      - Check the audit log of executed commands
+  cache      - Inspect or clear the response cache
+  chat       - Start a multi-turn conversation
+  doctor     - Diagnose configuration problems
+  examples   - Manage few-shot examples sent with every request
+  explain    - Explain what an existing shell command does
+  fix        - Repair the last failed shell command
+  history    - View your command history with an interactive interface
+  mcp        - Run a Model Context Protocol server over stdio
+  models     - List available AI models
+  plan       - Break a task into steps and run them one at a time
+  prompt     - Show or customize prompt templates
+  script     - Generate a reusable script file
+  serve      - Run a local JSON HTTP API
+  settings   - Configure API keys and preferences
+  shell-init - Print shell integration code (Ctrl+G widget)
+  usage      - Show token usage and cost
+  version    - Show version information
 
 Examples:
   ted agent how to make a python virtual environment
   ted ask how to find large files
+  ted audit verify
+  ted chat
+  ted doctor
+  ted explain 'tar -xzvf archive.tar.gz'
+  ted fix
   ted history
+  ted models
+  ted plan set up a Go project with a Makefile and a git repo
+  ted script back up my home directory -o backup.sh
+  ted serve --addr 127.0.0.1:8787
   ted settings
-  ted version`,
+  ted usage --days 7
+  ted version
+
+Logging:
+  --verbose logs config resolution, request latency, retries and cache hits to
+  stderr; --debug also logs the system instruction, rendered prompts, response
+  schemas and raw model output. Set TED_LOG instead to log every run, e.g.
+  TED_LOG=debug, or TED_LOG=debug,file to write ~/.ted/logs/ted.log (rotated
+  at 5 MB). Secrets are redacted from every record.`,
+	PersistentPreRunE: setupLogging,
+}
+
+// setupLogging configures the log from TED_LOG, with --verbose and --debug
+// overriding its level.
+func setupLogging(cmd *cobra.Command, args []string) error {
+	settings, err := logging.ParseEnv(os.Getenv(logging.EnvVar))
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	switch {
+	case debug:
+		settings.Enabled = true
+		settings.Level = slog.LevelDebug
+	case verbose:
+		settings.Enabled = true
+		settings.Level = slog.LevelInfo
+	}
+
+	if err := logging.Setup(settings, filepath.Join(config.GetConfigPath(), "logs")); err != nil {
+		return err
+	}
+	logging.Logger().Debug("command started", "command", cmd.CommandPath(), "version", Version)
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -41,4 +108,6 @@ func Execute() {
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log config, request latency and retries to stderr")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Also log prompts, schemas and raw model output")
 }
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/lint"
+	"github.com/jadenpxrk/ted/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scriptOutput   string
+	scriptLanguage string
+	scriptForce    bool
+	scriptYes      bool
+	scriptJSON     bool
+)
+
+var scriptCmd = &cobra.Command{
+	Use:   "script [description]",
+	Short: "Generate a reusable script file",
+	Long: `Generate a complete script with a shebang, strict mode, argument parsing
+and comments, instead of a one-liner.
+
+The script is checked locally before it is saved: its syntax with bash -n,
+zsh -n or Python's parser, sh scripts with dash -n when it is installed, and
+with shellcheck if it is installed. sh -n alone accepts some bash-only
+syntax, so without shellcheck sh scripts get a warning that they weren't
+checked for it. A new file is made executable, and so is an existing one
+when the script has a shebang.
+Scripts with syntax errors aren't saved unless --force is given. If the
+output file already exists, a diff is shown before it is overwritten.
+
+The language defaults to bash, or python when the output file ends in .py.
+Without --output the script is written to stdout.
+
+Example:
+  ted script deploy the current directory to a server over rsync -o deploy.sh
+  ted script back up a postgres database to s3 with a retention period -o backup.sh
+  ted script --lang python resize every image in a folder -o resize.py`,
+	RunE: runScript,
+}
+
+func runScript(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("please provide a description. Example: ted script back up my home directory -o backup.sh")
+	}
+
+	query := strings.Join(args, " ")
+
+	// Without --output the script itself goes to stdout.
+	quiet := scriptOutput == "" || scriptJSON
+	if quiet {
+		cmd.SilenceUsage = true
+	}
+
+	language := scriptLanguage
+	if !cmd.Flags().Changed("lang") && filepath.Ext(scriptOutput) == ".py" {
+		language = "python"
+	}
+	if !slices.Contains(lint.Languages, language) {
+		return fmt.Errorf("unsupported language %q, use one of: %s", language, strings.Join(lint.Languages, ", "))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	piped, err := readPipedStdin()
+	if err != nil {
+		return err
+	}
+
+	sh := resolveShell(cfg)
+	data := prompts.NewData(query, sh.Name)
+	data.Mode = "script"
+	data.Input = piped
+	data.Language = language
+
+	client, err := newClient(cfg, "script", data)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	prompt, err := prompts.Render(prompts.Script, cfg.Prompts, data)
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s\n", colors.ThinkingStyle.Render("Thinking..."))
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	response, err := client.GenerateScript(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("error generating script: %w", err)
+	}
+
+	result, err := lint.Check(ctx, language, response.Script)
+	if err != nil {
+		return err
+	}
+
+	if scriptJSON {
+		return writeJSON(struct {
+			*gemini.ScriptResponse
+			Language string   `json:"language"`
+			Errors   []string `json:"errors"`
+			Warnings []string `json:"warnings"`
+			responseMeta
+		}{response, language, result.Errors, result.Warnings, newResponseMeta(cfg.Model, time.Since(start), response.Usage)})
+	}
+
+	printLintResult(result)
+	if !result.OK() && !scriptForce {
+		return fmt.Errorf("the generated script has syntax errors and was not saved; run again, or use --force to save it anyway")
+	}
+
+	if scriptOutput == "" {
+		fmt.Print(response.Script)
+		if err := saveScriptToHistory(query, "(stdout)", response.Script, tokensOf(cfg.Model, response.Usage)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save to history: %v\n", err)
+		}
+		return nil
+	}
+
+	fmt.Printf("%s\n", colors.EntryStyle.Render(response.Explanation))
+
+	saved, err := writeScript(scriptOutput, response.Script)
+	if err != nil || !saved {
+		return err
+	}
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("Saved %s", scriptOutput)))
+
+	if err := saveScriptToHistory(query, scriptOutput, response.Script, tokensOf(cfg.Model, response.Usage)); err != nil {
+		fmt.Printf("Warning: Failed to save to history: %v\n", err)
+	}
+	return nil
+}
+
+// printLintResult writes the problems found in a script to stderr, so they
+// don't end up in a script written to stdout.
+func printLintResult(result *lint.Result) {
+	for _, message := range result.Errors {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.ErrorStyle.Render("✗ "+message))
+	}
+	for _, message := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.SettingsWarningStyle.Render("⚠️  "+message))
+	}
+}
+
+// writeScript saves script to path. If the file exists, it shows a diff and
+// asks before overwriting, unless --yes was given. The file is made
+// executable when it is new or the script starts with a shebang, so
+// overwriting a file such as notes.txt keeps its mode. It reports whether the
+// file was written.
+func writeScript(path, script string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	isNew := os.IsNotExist(err)
+	switch {
+	case isNew:
+	case err != nil:
+		return false, fmt.Errorf("error reading %s: %w", path, err)
+	case string(existing) == script:
+		fmt.Printf("%s\n", colors.SettingsInfoStyle.Render(fmt.Sprintf("%s is unchanged.", path)))
+		return false, nil
+	default:
+		fmt.Printf("\n%s\n%s\n", colors.HeaderStyle.Render(fmt.Sprintf("Changes to %s:", path)), renderDiff(string(existing), script))
+		if !scriptYes {
+			fmt.Printf("%s", colors.PromptStyle.Render(fmt.Sprintf("Overwrite %s? (y/N): ", path)))
+			scanner := bufio.NewScanner(terminalInput())
+			if !scanner.Scan() || !strings.EqualFold(strings.TrimSpace(scanner.Text()), "y") {
+				fmt.Println("Not saved.")
+				return false, nil
+			}
+		}
+	}
+
+	// WriteFile only applies the mode to a new file.
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return false, fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if isNew || !strings.HasPrefix(script, "#!") {
+		return true, nil
+	}
+
+	// Add the execute bits where the file is readable, keeping the rest of
+	// its mode.
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("error making %s executable: %w", path, err)
+	}
+	mode := info.Mode().Perm()
+	if err := os.Chmod(path, mode|(mode&0444)>>2); err != nil {
+		return false, fmt.Errorf("error making %s executable: %w", path, err)
+	}
+	return true, nil
+}
+
+func saveScriptToHistory(query, path, script string, tokens history.Tokens) error {
+	hist, err := history.Load()
+	if err != nil {
+		return err
+	}
+	defer hist.Close()
+
+	return hist.AddScript(query, path, script, tokens)
+}
+
+func init() {
+	scriptCmd.Flags().StringVarP(&scriptOutput, "output", "o", "", "File to save the script to")
+	scriptCmd.Flags().StringVar(&scriptLanguage, "lang", "bash", "Script language: bash, sh, zsh or python")
+	scriptCmd.Flags().BoolVar(&scriptForce, "force", false, "Save the script even if it has syntax errors")
+	scriptCmd.Flags().BoolVarP(&scriptYes, "yes", "y", false, "Overwrite an existing file without asking")
+	scriptCmd.Flags().BoolVar(&scriptJSON, "json", false, "Print the script and check results as JSON without saving")
+	rootCmd.AddCommand(scriptCmd)
+}
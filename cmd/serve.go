@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/redact"
+	"github.com/jadenpxrk/ted/pkg/ted"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultServeAddr is where 'ted serve' listens by default.
+	DefaultServeAddr = "127.0.0.1:8787"
+
+	// maxRequestBody caps the size of a request body.
+	maxRequestBody = 1 << 20
+)
+
+var (
+	serveAddr  string
+	serveToken string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local JSON HTTP API",
+	Long: `Serve ted's agent, ask, explain and history operations as a JSON HTTP API,
+so editor extensions and dashboards can reuse your configured model, prompt
+templates, examples, cache and history.
+
+Endpoints:
+  POST /v1/agent     {"query": "...", "input": "...", "tools": true}
+  POST /v1/ask       {"query": "...", "input": "...", "count": 3}
+  POST /v1/explain   {"command": "..."}
+  GET  /v1/history
+  GET  /health
+
+Responses have the same shape as the --json output of the matching command.
+Errors are returned as {"error": "..."}. The server never runs commands; it
+only suggests and explains them.
+
+POST requests must be sent with "Content-Type: application/json", and
+history entries are returned with secrets redacted.
+
+Pass --token, or set TED_SERVE_TOKEN, to require an
+"Authorization: Bearer <token>" header on every /v1 request. Listening on an
+address other than loopback requires a token. Without one, requests must be
+addressed to localhost or a loopback IP in their Host header, so web pages
+can't reach the API by pointing their own domain at 127.0.0.1.
+
+Example:
+  ted serve --addr 127.0.0.1:8787
+  curl -s localhost:8787/v1/agent -H 'Content-Type: application/json' \
+    -d '{"query": "list open ports"}'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runServe,
+}
+
+// agentRequest is the body of POST /v1/agent. Tools defaults to true and
+// only turns off the tools enabled in config.yaml.
+type agentRequest struct {
+	Query string `json:"query"`
+	Input string `json:"input"`
+	Tools *bool  `json:"tools"`
+}
+
+// askRequest is the body of POST /v1/ask. Count defaults to ask_count.
+type askRequest struct {
+	Query string `json:"query"`
+	Input string `json:"input"`
+	Count int    `json:"count"`
+}
+
+// explainRequest is the body of POST /v1/explain.
+type explainRequest struct {
+	Command string `json:"command"`
+}
+
+// server handles API requests with a client created at startup.
+type server struct {
+	client *ted.Client
+	token  string
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("TED_SERVE_TOKEN")
+	}
+
+	host, _, err := net.SplitHostPort(serveAddr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", serveAddr, err)
+	}
+	if token == "" && !isLoopback(host) {
+		return fmt.Errorf("refusing to listen on %s without a token; pass --token or set TED_SERVE_TOKEN", serveAddr)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	client, err := newTedClient(cfg, ted.WithShell(resolveShell(cfg).Name))
+	if err != nil {
+		return err
+	}
+
+	s := &server{client: client, token: token}
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.Serve(listener)
+	}()
+
+	auth := "no token"
+	if token != "" {
+		auth = "bearer token required"
+	}
+	fmt.Printf("%s\n", colors.SuccessStyle.Render(fmt.Sprintf("Serving on http://%s (%s). Press Ctrl+C to stop.", listener.Addr(), auth)))
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeHTTPJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": Version, "model": s.client.Model()})
+	})
+	mux.Handle("POST /v1/agent", s.authorize(s.handleAgent))
+	mux.Handle("POST /v1/ask", s.authorize(s.handleAsk))
+	mux.Handle("POST /v1/explain", s.authorize(s.handleExplain))
+	mux.Handle("GET /v1/history", s.authorize(s.handleHistory))
+
+	return logRequests(s.checkHost(mux))
+}
+
+// checkHost rejects requests for a host other than loopback when no token is
+// set. Without this, a web page could use DNS rebinding to make the browser
+// send requests from its own origin to the server and read the responses.
+func (s *server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = r.Host
+			}
+			if !isLoopback(strings.ToLower(strings.Trim(host, "[]"))) {
+				writeHTTPError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed without a token", r.Host))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize rejects requests without the bearer token, if one is set.
+func (s *server) authorize(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeHTTPError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+				return
+			}
+		}
+		next(w, r)
+	})
+}
+
+func (s *server) handleAgent(w http.ResponseWriter, r *http.Request) {
+	var body agentRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if strings.TrimSpace(body.Query) == "" {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("query is required"))
+		return
+	}
+
+	opts := []ted.CallOption{ted.WithInput(body.Input)}
+	if body.Tools != nil && !*body.Tools {
+		opts = append(opts, ted.WithoutTools())
+	}
+
+	suggestion, err := s.client.Suggest(r.Context(), body.Query, opts...)
+	if err != nil {
+		writeHTTPError(w, providerStatus(err), err)
+		return
+	}
+
+	writeHTTPJSON(w, http.StatusOK, struct {
+		*ted.Suggestion
+		responseMeta
+	}{suggestion, metaOf(suggestion.Meta)})
+}
+
+func (s *server) handleAsk(w http.ResponseWriter, r *http.Request) {
+	var body askRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if strings.TrimSpace(body.Query) == "" {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("query is required"))
+		return
+	}
+	if body.Count < 0 || body.Count > config.MaxAskCount {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("count must be between 1 and %d", config.MaxAskCount))
+		return
+	}
+
+	opts := []ted.CallOption{ted.WithInput(body.Input)}
+	if body.Count > 0 {
+		opts = append(opts, ted.WithCount(body.Count))
+	}
+
+	suggestions, err := s.client.Ask(r.Context(), body.Query, opts...)
+	if err != nil {
+		writeHTTPError(w, providerStatus(err), err)
+		return
+	}
+
+	writeHTTPJSON(w, http.StatusOK, struct {
+		*ted.Suggestions
+		responseMeta
+	}{suggestions, metaOf(suggestions.Meta)})
+}
+
+func (s *server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	var body explainRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	command := strings.TrimSpace(body.Command)
+	if command == "" {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("command is required"))
+		return
+	}
+
+	explanation, err := s.client.Explain(r.Context(), command)
+	if err != nil {
+		writeHTTPError(w, providerStatus(err), err)
+		return
+	}
+
+	writeHTTPJSON(w, http.StatusOK, struct {
+		*ted.Explanation
+		responseMeta
+	}{explanation, metaOf(explanation.Meta)})
+}
+
+func (s *server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.client.History(r.Context())
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	redactHistory(entries)
+	writeHTTPJSON(w, http.StatusOK, struct {
+		Entries []ted.HistoryEntry `json:"entries"`
+	}{entries})
+}
+
+// decodeBody decodes the JSON request body into v, writing an error response
+// and returning false if it is invalid. Only application/json bodies are
+// accepted: browsers send other types, such as text/plain, from any page
+// without asking the server first.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeHTTPError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json"))
+		return false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// providerStatus maps a generation error to an HTTP status.
+func providerStatus(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return 499
+	case errors.Is(err, ted.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ted.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ted.ErrBlocked):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+// writeHTTPError writes err as the response. Provider errors can quote the
+// request URL, so secrets are redacted.
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	writeHTTPJSON(w, status, map[string]string{"error": redact.String(err.Error())})
+}
+
+// statusRecorder remembers the status written by a handler for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs each request to stderr once it has been handled.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Fprintf(os.Stderr, "%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", DefaultServeAddr, "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on API requests")
+	rootCmd.AddCommand(serveCmd)
+}
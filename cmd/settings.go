@@ -2,13 +2,15 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
-	"ted/internal/colors"
-	"ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/models"
 
 	"github.com/spf13/cobra"
 )
@@ -51,30 +53,28 @@ func runSettings(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("\n%s %s\n", colors.SettingsLabelStyle.Render("Current model:"), colors.SettingsValueStyle.Render(cfg.Model))
 	fmt.Printf("%s\n", colors.HeaderStyle.Render("Available models:"))
-	models := []string{
-		"gemini-2.0-flash",
-		"gemini-2.0-flash-lite",
-		"gemini-2.5-pro-preview-05-06",
-		"gemini-2.5-flash-preview-05-20",
+	available, source, err := models.List(context.Background(), cfg.GeminiAPIKey, false)
+	if err != nil && source == models.SourceBuiltin {
+		fmt.Printf("%s\n", colors.SettingsInfoStyle.Render("Could not fetch the model list, showing built-in models."))
 	}
 
-	for i, model := range models {
-		fmt.Printf("  %s", colors.SettingsOptionStyle.Render(fmt.Sprintf("%d. %s", i+1, model)))
-		if model == "gemini-2.0-flash" {
+	for i, model := range available {
+		fmt.Printf("  %s", colors.SettingsOptionStyle.Render(fmt.Sprintf("%d. %s", i+1, model.Name)))
+		if model.Name == config.DefaultModel {
 			fmt.Printf(" %s", colors.SettingsConfiguredStyle.Render("(default)"))
 		}
 		fmt.Println()
 	}
-	fmt.Printf("%s ", colors.PromptStyle.Render("Enter number (1-4) or press Enter to keep current:"))
+	fmt.Printf("%s ", colors.PromptStyle.Render(fmt.Sprintf("Enter number (1-%d) or press Enter to keep current:", len(available))))
 
 	if scanner.Scan() {
 		input := strings.TrimSpace(scanner.Text())
 		if input != "" {
-			if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(models) {
-				cfg.Model = models[num-1]
+			if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(available) {
+				cfg.Model = available[num-1].Name
 				fmt.Printf("%s\n", colors.SuccessStyle.Render("✓ Model updated"))
 			} else {
-				fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  Invalid selection '%s'. Please enter a number between 1 and %d.", input, len(models))))
+				fmt.Printf("%s\n", colors.SettingsWarningStyle.Render(fmt.Sprintf("⚠️  Invalid selection '%s'. Please enter a number between 1 and %d.", input, len(available))))
 			}
 		}
 	}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/shell"
+
+	"github.com/spf13/cobra"
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init [zsh|bash|fish]",
+	Short: "Print shell integration code",
+	Long: `Print shell integration code that binds Ctrl+G to ted.
+
+Type a request on the command line and press Ctrl+G: the line is sent to
+ted and replaced with the suggested command, ready to edit or run in your
+own shell, with your aliases and functions, and saved to your shell history.
+
+Setup:
+  zsh:   eval "$(ted shell-init zsh)"     # in ~/.zshrc
+  bash:  eval "$(ted shell-init bash)"    # in ~/.bashrc
+  fish:  ted shell-init fish | source     # in ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shell.Names,
+	RunE:      runShellInit,
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	script, err := shell.InitScript(args[0])
+	if err != nil {
+		return fmt.Errorf("%w. Supported shells: %s", err, strings.Join(shell.Names, ", "))
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/redact"
+)
+
+const (
+	// maxStdinBytes caps how much piped input is sent to the model.
+	maxStdinBytes = 32 * 1024
+
+	// stdinIdleTimeout is how long ted waits for more piped input before
+	// going on with what it has, so a pipe that an editor or CI runner
+	// leaves open doesn't hang it.
+	stdinIdleTimeout = 5 * time.Second
+)
+
+// readPipedStdin returns input piped or redirected into ted, truncated to
+// maxStdinBytes and with secrets redacted. It returns "" when stdin is a
+// terminal or other character device such as /dev/null. Input cut short by
+// the size limit or stdinIdleTimeout is marked, and a warning printed.
+func readPipedStdin() (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", nil
+	}
+
+	data, complete, err := readUntilIdle(os.Stdin, maxStdinBytes+1, stdinIdleTimeout)
+	if err != nil {
+		return "", fmt.Errorf("error reading stdin: %w", err)
+	}
+
+	input := string(data)
+	switch {
+	case len(data) > maxStdinBytes:
+		input = string(data[:maxStdinBytes]) + "\n[... input truncated ...]"
+		fmt.Fprintf(os.Stderr, "Warning: piped input is over %d KB, only the first %d KB is used\n", maxStdinBytes/1024, maxStdinBytes/1024)
+	case !complete && len(data) > 0:
+		input += "\n[... input may be incomplete ...]"
+		fmt.Fprintf(os.Stderr, "Warning: no piped input for %s but stdin is still open, using the %d bytes read so far\n", stdinIdleTimeout, len(data))
+	}
+
+	return redact.String(input), nil
+}
+
+// readUntilIdle reads from r until EOF, limit bytes or idle passes without
+// any input. complete reports whether it stopped at EOF or the limit. After
+// a timeout the read in progress is abandoned, so r mustn't be used again.
+func readUntilIdle(r io.Reader, limit int, idle time.Duration) (data []byte, complete bool, err error) {
+	type chunk struct {
+		data []byte
+		err  error
+	}
+	chunks := make(chan chunk)
+	go func() {
+		defer close(chunks)
+		lr := io.LimitReader(r, int64(limit))
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := lr.Read(buf)
+			chunks <- chunk{buf[:n], err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	for {
+		select {
+		case c, ok := <-chunks:
+			if !ok {
+				return data, true, nil
+			}
+			data = append(data, c.data...)
+			if c.err == io.EOF {
+				return data, true, nil
+			}
+			if c.err != nil {
+				return nil, false, c.err
+			}
+			timer.Reset(idle)
+		case <-timer.C:
+			return data, false, nil
+		}
+	}
+}
+
+// terminalInput returns the file interactive input should be read from:
+// stdin when it is a terminal, otherwise /dev/tty so prompts still work when
+// content is piped into ted.
+var terminalInput = sync.OnceValue(func() *os.File {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return os.Stdin
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return os.Stdin
+	}
+	return tty
+})
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageDays int
+	usageJSON bool
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and cost",
+	Long: `Show how many tokens ted has used and what they cost.
+
+Every request to the model is logged in ~/.ted/history.db with its prompt
+and response token counts. This command totals them for the last --days
+days and breaks them down by day, model and mode. Cached answers use no
+tokens and aren't counted.
+
+Costs use the built-in price list, which you can override or extend per
+model in config.yaml, in USD per million tokens:
+
+  pricing:
+    gemini-2.0-flash:
+      input: 0.10
+      output: 0.40
+
+Set monthly_limit to an amount in USD to be warned before each request once
+the current month's spending reaches it. The limit is a warning only.`,
+	Args: cobra.NoArgs,
+	RunE: runUsage,
+}
+
+// usageReport is the --json output of 'ted usage'.
+type usageReport struct {
+	Since        time.Time `json:"since"`
+	MonthCost    float64   `json:"month_cost_usd"`
+	MonthlyLimit float64   `json:"monthly_limit_usd,omitempty"`
+	usage.Report
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	if usageDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	if usageJSON {
+		cmd.SilenceUsage = true
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-usageDays)
+	monthStart := usage.MonthStart(now)
+
+	start := since
+	if monthStart.Before(start) {
+		start = monthStart
+	}
+	records, err := hist.GetUsage(start)
+	if err != nil {
+		return err
+	}
+
+	var recent, month []history.UsageRecord
+	for _, record := range records {
+		if !record.Time.Before(since) {
+			recent = append(recent, record)
+		}
+		if !record.Time.Before(monthStart) {
+			month = append(month, record)
+		}
+	}
+
+	report := usageReport{
+		Since:        since,
+		MonthCost:    usage.Summarize(month, cfg.Pricing).Total.Cost,
+		MonthlyLimit: cfg.MonthlyLimit,
+		Report:       usage.Summarize(recent, cfg.Pricing),
+	}
+
+	if usageJSON {
+		return writeJSON(report)
+	}
+
+	printUsageReport(report)
+	return nil
+}
+
+func printUsageReport(report usageReport) {
+	fmt.Printf("%s\n", colors.TitleStyle.Render(fmt.Sprintf("Token Usage (last %d days)", usageDays)))
+
+	if report.Total.Requests == 0 {
+		fmt.Printf("%s\n", colors.QueryStyle.Render("No requests in this period."))
+	} else {
+		printCacheStat("Requests", fmt.Sprintf("%d", report.Total.Requests))
+		printCacheStat("Tokens", fmt.Sprintf("%s in, %s out", formatTokens(report.Total.PromptTokens), formatTokens(report.Total.ResponseTokens)))
+		printCacheStat("Cost", formatCost(report.Total))
+	}
+
+	month := fmt.Sprintf("$%.4f", report.MonthCost)
+	if report.MonthlyLimit > 0 {
+		month += fmt.Sprintf(" of $%.2f limit", report.MonthlyLimit)
+	}
+	printCacheStat("Month", month)
+	if report.MonthlyLimit > 0 && report.MonthCost >= report.MonthlyLimit {
+		fmt.Printf("%s\n", colors.SettingsWarningStyle.Render("⚠️  Monthly limit reached"))
+	}
+
+	if report.Total.Requests == 0 {
+		return
+	}
+
+	printUsageGroups("By day", report.ByDay)
+	printUsageGroups("By model", report.ByModel)
+	printUsageGroups("By mode", report.ByMode)
+
+	if report.Total.Unpriced > 0 {
+		fmt.Printf("\n%s\n", colors.TimeStyle.Render("* Some models have no price; add them under 'pricing' in config.yaml."))
+	}
+}
+
+func printUsageGroups(title string, groups []usage.Group) {
+	width := 0
+	for _, group := range groups {
+		width = max(width, len(group.Key))
+	}
+
+	fmt.Printf("\n%s\n", colors.HeaderStyle.Render(title))
+	for _, group := range groups {
+		fmt.Printf("  %s %s %s\n",
+			colors.SettingsLabelStyle.Render(fmt.Sprintf("%-*s", width, group.Key)),
+			colors.SettingsValueStyle.Render(fmt.Sprintf("%5d req  %9s in  %9s out", group.Requests, formatTokens(group.PromptTokens), formatTokens(group.ResponseTokens))),
+			colors.CommandStyle.Render(formatCost(group.Totals)))
+	}
+}
+
+// formatTokens abbreviates a token count, e.g. 1.2k or 3.4M.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// formatCost formats a cost in USD, marking totals that include requests to
+// models without a price.
+func formatCost(totals usage.Totals) string {
+	cost := fmt.Sprintf("$%.4f", totals.Cost)
+	if totals.Unpriced > 0 {
+		cost += "*"
+	}
+	return cost
+}
+
+// warnMonthlyLimit prints a warning to stderr when this month's spending has
+// reached the configured soft limit.
+func warnMonthlyLimit(cfg *config.Config) {
+	if cfg.MonthlyLimit <= 0 {
+		return
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return
+	}
+	records, err := hist.GetUsage(usage.MonthStart(time.Now()))
+	hist.Close()
+	if err != nil {
+		return
+	}
+
+	spent := usage.Summarize(records, cfg.Pricing).Total.Cost
+	if spent >= cfg.MonthlyLimit {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.SettingsWarningStyle.Render(
+			fmt.Sprintf("⚠️  You have spent $%.2f this month, over your $%.2f limit. Run 'ted usage' for details.", spent, cfg.MonthlyLimit)))
+	}
+}
+
+// tokensOf converts the usage of a response for storage in history.
+func tokensOf(model string, u gemini.Usage) history.Tokens {
+	return history.Tokens{
+		Model:          model,
+		PromptTokens:   u.PromptTokens,
+		ResponseTokens: u.ResponseTokens,
+	}
+}
+
+func init() {
+	usageCmd.Flags().IntVar(&usageDays, "days", 30, "Number of days to include")
+	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(usageCmd)
+}
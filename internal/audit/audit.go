@@ -0,0 +1,252 @@
+// Package audit keeps an append-only log of the commands ted runs, separate
+// from the history, which is pruned and can be cleared. Each record is a
+// line of JSON holding the hash of the record before it, so editing,
+// reordering or removing a record breaks the chain that Verify checks.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the audit log in ~/.ted unless audit_log is set.
+const FileName = "audit.log"
+
+// genesis is the previous hash of the first record.
+var genesis = strings.Repeat("0", sha256.Size*2)
+
+// Record describes one executed command.
+type Record struct {
+	Seq  int64  `json:"seq"`
+	Prev string `json:"prev"`
+	Hash string `json:"hash,omitempty"`
+
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	User     string    `json:"user"`
+	Host     string    `json:"host"`
+	Cwd      string    `json:"cwd"`
+	Shell    string    `json:"shell"`
+
+	// Mode is the ted command that ran it, e.g. agent or ask. Query is what
+	// the user asked for.
+	Mode  string `json:"mode"`
+	Query string `json:"query"`
+
+	// Command is what ran. If the user edited the model's suggestion first,
+	// Edited is set and Suggested holds the original.
+	Command   string `json:"command"`
+	Suggested string `json:"suggested,omitempty"`
+	Edited    bool   `json:"edited"`
+
+	// ExitCode is -1 if the command was killed by a signal or couldn't be
+	// started; Error says why.
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Log is an open audit log.
+type Log struct {
+	file *os.File
+}
+
+// Open opens the audit log at path for appending, creating it and its
+// directory if needed. Opening the log before running a command makes sure
+// the command can be recorded.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Log{file: file}, nil
+}
+
+// Close closes the log.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
+
+// Append chains record to the end of the log and returns it with its
+// sequence number and hashes. The user, host and working directory are
+// filled in if empty. Appends from concurrent ted processes are serialized
+// with a file lock.
+func (l *Log) Append(record Record) (Record, error) {
+	fillEnvironment(&record)
+	record.Started = record.Started.UTC()
+	record.Finished = record.Finished.UTC()
+
+	if err := lockFile(l.file); err != nil {
+		return Record{}, fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer unlockFile(l.file)
+
+	last, err := lastLine(l.file)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	record.Seq = 1
+	record.Prev = genesis
+	if len(last) > 0 {
+		var prev Record
+		if err := json.Unmarshal(last, &prev); err != nil {
+			return Record{}, fmt.Errorf("the last audit record is corrupt, run 'ted audit verify': %w", err)
+		}
+		record.Seq = prev.Seq + 1
+		record.Prev = prev.Hash
+	}
+
+	record.Hash, err = hashRecord(record)
+	if err != nil {
+		return Record{}, err
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return Record{}, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return Record{}, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return record, nil
+}
+
+// hashRecord returns the SHA-256 of record's JSON without its own hash. The
+// JSON includes Prev, which chains it to the record before.
+func hashRecord(record Record) (string, error) {
+	record.Hash = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lastLine returns the last line of file without its newline, or nil if the
+// file is empty. It reads backwards so appending stays fast as the log grows.
+func lastLine(file *os.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	const chunk = 4096
+	var tail []byte
+	for offset := size; offset > 0; {
+		n := min(int64(chunk), offset)
+		offset -= n
+		buf := make([]byte, n)
+		if _, err := file.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+	return bytes.TrimRight(tail, "\n"), nil
+}
+
+func fillEnvironment(record *Record) {
+	if record.User == "" {
+		if u, err := user.Current(); err == nil {
+			record.User = u.Username
+		} else {
+			record.User = os.Getenv("USER")
+		}
+	}
+	if record.Host == "" {
+		record.Host, _ = os.Hostname()
+	}
+	if record.Cwd == "" {
+		record.Cwd, _ = os.Getwd()
+	}
+}
+
+// Summary describes a log that passed verification.
+type Summary struct {
+	Records int
+	// Head is the hash of the last record, or empty for an empty log.
+	// Keeping a copy elsewhere also detects records removed from the end.
+	Head string
+}
+
+// ChainError reports the first record that breaks the chain.
+type ChainError struct {
+	Line    int
+	Problem string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Problem)
+}
+
+// Verify reads the log at path and checks that every record is intact and
+// chained to the one before. It returns a *ChainError for the first broken
+// record. A missing log verifies as empty.
+func Verify(path string) (Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Summary{}, nil
+		}
+		return Summary{}, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var summary Summary
+	prev := genesis
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	if len(data) == 0 {
+		lines = nil
+	}
+	for i, line := range lines {
+		n := i + 1
+
+		var record Record
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			return summary, &ChainError{Line: n, Problem: fmt.Sprintf("not a valid record: %v", err)}
+		}
+		if record.Seq != int64(n) {
+			return summary, &ChainError{Line: n, Problem: fmt.Sprintf("sequence number is %d, expected %d; records were removed or reordered", record.Seq, n)}
+		}
+		if record.Prev != prev {
+			return summary, &ChainError{Line: n, Problem: "previous hash doesn't match the record before; records were removed, inserted or modified"}
+		}
+		hash, err := hashRecord(record)
+		if err != nil {
+			return summary, err
+		}
+		if record.Hash != hash {
+			return summary, &ChainError{Line: n, Problem: "hash doesn't match the record's contents; the record was modified"}
+		}
+
+		prev = record.Hash
+		summary.Records = n
+		summary.Head = record.Hash
+	}
+	return summary, nil
+}
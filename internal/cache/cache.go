@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/history"
+)
+
+// DefaultTTL is how long responses are cached by default.
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Request identifies a model request for caching. Two requests with the same
+// key are expected to get equivalent answers.
+type Request struct {
+	Mode        string
+	Provider    string
+	Model       string
+	Temperature float32
+	Query       string
+	OS          string
+	Shell       string
+
+	// Context holds anything else the answer depends on, such as the prompt
+	// templates, piped input or the working directory.
+	Context []string
+}
+
+// Key returns the cache key for r, built from a hash of its fields with the
+// query normalized.
+func (r Request) Key() string {
+	hash := sha256.New()
+	fields := []string{
+		r.Mode,
+		r.Provider,
+		r.Model,
+		strconv.FormatFloat(float64(r.Temperature), 'f', -1, 32),
+		Normalize(r.Query),
+		r.OS,
+		r.Shell,
+	}
+	for _, field := range append(fields, r.Context...) {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	return r.Mode + ":" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Normalize folds case, whitespace and trailing punctuation so trivially
+// different phrasings of a query share a key.
+func Normalize(query string) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	return strings.TrimRight(query, "?.! ")
+}
+
+// Get decodes the cached response for r into v. It returns the cache entry,
+// or nil if there is no unexpired entry.
+func Get(r Request, v any) (*history.CacheEntry, error) {
+	hist, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	defer hist.Close()
+
+	entry, err := hist.GetCached(r.Key())
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(entry.Response, v); err != nil {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// Put caches response v for r for ttl.
+func Put(r Request, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return err
+	}
+	defer hist.Close()
+
+	now := time.Now()
+	return hist.PutCached(r.Key(), history.CacheEntry{
+		Mode:     r.Mode,
+		Query:    r.Query,
+		Model:    r.Model,
+		Created:  now,
+		Expires:  now.Add(ttl),
+		Response: data,
+	})
+}
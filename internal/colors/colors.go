@@ -98,4 +98,39 @@ var (
 
 	SettingsInfoStyle = lipgloss.NewStyle().
 				Foreground(CyanColor)
+
+	RiskLowStyle = lipgloss.NewStyle().
+			Foreground(SecondaryColor).
+			Bold(true)
+
+	RiskMediumStyle = lipgloss.NewStyle().
+			Foreground(AccentColor).
+			Bold(true)
+
+	RiskHighStyle = lipgloss.NewStyle().
+			Foreground(ErrorColor).
+			Bold(true)
+
+	BadgeStyle = lipgloss.NewStyle().
+			Foreground(MutedColor)
+
+	DiffAddStyle = lipgloss.NewStyle().
+			Foreground(SecondaryColor)
+
+	DiffRemoveStyle = lipgloss.NewStyle().
+			Foreground(ErrorColor)
 )
+
+// RiskStyle returns the style used to highlight the given risk level.
+func RiskStyle(level string) lipgloss.Style {
+	switch level {
+	case "high":
+		return RiskHighStyle
+	case "medium":
+		return RiskMediumStyle
+	case "low":
+		return RiskLowStyle
+	default:
+		return BadgeStyle
+	}
+}
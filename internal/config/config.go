@@ -1,17 +1,94 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/cache"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/logging"
+	"github.com/jadenpxrk/ted/internal/tools"
+	"github.com/jadenpxrk/ted/internal/usage"
 
 	"github.com/spf13/viper"
 )
 
+const (
+	// DefaultModel is the model used when none has been configured.
+	DefaultModel = "gemini-2.0-flash"
+
+	// DefaultAskCount is the number of suggestions ask mode requests by default.
+	DefaultAskCount = 3
+
+	// MaxAskCount is the largest number of suggestions ask mode can request.
+	MaxAskCount = 10
+)
+
 type Config struct {
 	GeminiAPIKey string  `mapstructure:"gemini_api_key"`
 	Model        string  `mapstructure:"model"`
 	Temperature  float32 `mapstructure:"temperature"`
+	AskCount     int     `mapstructure:"ask_count"`
+
+	// Shell runs suggested commands; empty means $SHELL. ShellRC is sourced
+	// first so aliases and functions are available.
+	Shell   string `mapstructure:"shell"`
+	ShellRC string `mapstructure:"shell_rc"`
+
+	// Prompts overrides prompt templates by name, one of prompts.Names.
+	Prompts map[string]string `mapstructure:"prompts"`
+
+	// Tools lists the read-only tools agent mode may call; empty disables
+	// them. ToolOutputLimit caps the bytes of each tool's output sent back.
+	Tools           []string `mapstructure:"tools"`
+	ToolOutputLimit int      `mapstructure:"tool_output_limit"`
+
+	// CacheTTL is how long agent, ask and explain responses are cached; zero
+	// disables the cache.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+
+	// RequestTimeout bounds each request to the provider, and MaxRetries is
+	// how often a rate-limited or failed request is retried.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	MaxRetries     int           `mapstructure:"max_retries"`
+
+	// Pricing overrides the built-in USD price per million tokens by model.
+	// It is decoded separately because viper splits keys on the dots in
+	// model names.
+	Pricing map[string]usage.Price `mapstructure:"-"`
+
+	// MonthlyLimit is a soft limit in USD; requests warn once the month's
+	// spending reaches it. Zero disables the warning.
+	MonthlyLimit float64 `mapstructure:"monthly_limit"`
+
+	// AuditLog is where executed commands are recorded; empty means
+	// ~/.ted/audit.log.
+	AuditLog string `mapstructure:"audit_log"`
+
+	// ExecTimeout stops a suggested command that runs longer; zero means no
+	// timeout. CaptureLimit is how many bytes of the end of its output are
+	// saved with the history entry; zero disables capture.
+	ExecTimeout  time.Duration `mapstructure:"exec_timeout"`
+	CaptureLimit int           `mapstructure:"capture_limit"`
+
+	// Limits are resource limits for suggested commands, applied on Linux.
+	Limits Limits `mapstructure:"limits"`
+}
+
+// Limits are the resource limits set under limits in config.yaml. Zero
+// values are unlimited.
+type Limits struct {
+	CPU        time.Duration `mapstructure:"cpu"`
+	MemoryMB   int64         `mapstructure:"memory_mb"`
+	FileSizeMB int64         `mapstructure:"file_size_mb"`
 }
 
 func getConfigPath() (string, error) {
@@ -22,7 +99,29 @@ func getConfigPath() (string, error) {
 	return filepath.Join(homeDir, ".ted"), nil
 }
 
+// viperMu serializes Load and Save, which share viper's global instance, so
+// that library clients can be created from several goroutines.
+var viperMu sync.Mutex
+
+// ErrNoConfigFile is returned by Read when config.yaml doesn't exist.
+var ErrNoConfigFile = errors.New("config file does not exist")
+
+// Load reads config.yaml, creating it and the config directory with the
+// defaults if they are missing.
 func Load() (*Config, error) {
+	return load(true)
+}
+
+// Read reads config.yaml like Load but changes nothing on disk, returning
+// ErrNoConfigFile if the file is missing. It is for diagnostics.
+func Read() (*Config, error) {
+	return load(false)
+}
+
+func load(create bool) (*Config, error) {
+	viperMu.Lock()
+	defer viperMu.Unlock()
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return nil, err
@@ -31,16 +130,28 @@ func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(configPath)
+	viper.SetConfigPermissions(0600)
 
-	viper.SetDefault("model", "gemini-2.0-flash")
+	viper.SetDefault("model", DefaultModel)
 	viper.SetDefault("temperature", 0.3)
+	viper.SetDefault("ask_count", DefaultAskCount)
+	viper.SetDefault("tools", tools.DefaultNames)
+	viper.SetDefault("tool_output_limit", tools.DefaultOutputLimit)
+	viper.SetDefault("cache_ttl", cache.DefaultTTL.String())
+	viper.SetDefault("request_timeout", gemini.DefaultTimeout.String())
+	viper.SetDefault("max_retries", gemini.DefaultMaxRetries)
 
-	if err := os.MkdirAll(configPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	if create {
+		if err := os.MkdirAll(configPath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create config directory: %w", err)
+		}
 	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			if !create {
+				return nil, ErrNoConfigFile
+			}
 			if err := viper.SafeWriteConfig(); err != nil {
 				return nil, fmt.Errorf("failed to write config file: %w", err)
 			}
@@ -53,11 +164,80 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.Pricing = decodePricing(viper.Get("pricing"))
+	logConfig(&config)
 
 	return &config, nil
 }
 
+// logConfig records the resolved settings that shape requests and, at debug
+// level, whether each key came from config.yaml or a default. The API key is
+// only reported as set or not.
+func logConfig(config *Config) {
+	logger := logging.Logger()
+	logger.Info("config loaded",
+		"file", viper.ConfigFileUsed(),
+		"model", config.Model,
+		"temperature", strconv.FormatFloat(float64(config.Temperature), 'g', -1, 32),
+		"api_key_set", config.GeminiAPIKey != "",
+		"shell", config.Shell,
+		"tools", config.Tools,
+		"cache_ttl", config.CacheTTL,
+		"request_timeout", config.RequestTimeout,
+		"max_retries", config.MaxRetries,
+	)
+
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sources := make([]any, 0, len(keys))
+	for _, key := range keys {
+		source := "unset"
+		switch {
+		case viper.InConfig(key):
+			source = "file"
+		case viper.IsSet(key):
+			source = "default"
+		}
+		sources = append(sources, slog.String(key, source))
+	}
+	logger.Debug("config sources", slog.Group("keys", sources...))
+}
+
+// decodePricing reads the pricing map from config.yaml, skipping entries
+// that Validate reports as invalid.
+func decodePricing(value any) map[string]usage.Price {
+	raw, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	pricing := make(map[string]usage.Price, len(raw))
+	for model, entry := range raw {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		input, inputOK := toFloat(fields["input"])
+		output, outputOK := toFloat(fields["output"])
+		if !inputOK || !outputOK {
+			continue
+		}
+		pricing[model] = usage.Price{Input: input, Output: output}
+	}
+	return pricing
+}
+
 func Save(config *Config) error {
+	viperMu.Lock()
+	defer viperMu.Unlock()
+
 	viper.Set("gemini_api_key", config.GeminiAPIKey)
 	viper.Set("model", config.Model)
 	viper.Set("temperature", config.Temperature)
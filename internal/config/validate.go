@@ -0,0 +1,452 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/shell"
+	"github.com/jadenpxrk/ted/internal/tools"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue describes a problem found while validating config.yaml.
+type Issue struct {
+	Key     string
+	Problem string
+	Fix     string
+}
+
+type valueKind string
+
+const (
+	kindString valueKind = "string"
+	kindNumber valueKind = "number"
+	kindInt    valueKind = "integer"
+	kindMap    valueKind = "map"
+	kindList   valueKind = "list"
+	// kindDuration is a duration string such as "24h", or 0, which YAML
+	// reads as an integer.
+	kindDuration valueKind = "duration"
+)
+
+// schema lists every key config.yaml may contain and the type of its value.
+var schema = map[string]valueKind{
+	"gemini_api_key":    kindString,
+	"model":             kindString,
+	"temperature":       kindNumber,
+	"ask_count":         kindInt,
+	"shell":             kindString,
+	"shell_rc":          kindString,
+	"prompts":           kindMap,
+	"tools":             kindList,
+	"tool_output_limit": kindInt,
+	"cache_ttl":         kindDuration,
+	"request_timeout":   kindDuration,
+	"max_retries":       kindInt,
+	"pricing":           kindMap,
+	"monthly_limit":     kindNumber,
+	"audit_log":         kindString,
+	"exec_timeout":      kindDuration,
+	"capture_limit":     kindInt,
+	"limits":            kindMap,
+}
+
+// GetConfigFile returns the path of config.yaml.
+func GetConfigFile() string {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configPath, "config.yaml")
+}
+
+// Validate checks config.yaml against the schema and returns every problem
+// found. A nil slice means the file is valid.
+func Validate() ([]Issue, error) {
+	path := GetConfigFile()
+	if path == "" {
+		return nil, fmt.Errorf("failed to determine config path")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Issue{{
+				Problem: "config file does not exist",
+				Fix:     "Run 'ted settings' to create it",
+			}}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return []Issue{{
+			Problem: fmt.Sprintf("config file is not valid YAML: %v", err),
+			Fix:     fmt.Sprintf("Fix the syntax in %s or delete it and run 'ted settings'", path),
+		}}, nil
+	}
+
+	var issues []Issue
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kind, ok := schema[key]
+		if !ok {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: "unknown key",
+				Fix:     fmt.Sprintf("Remove '%s' from %s", key, path),
+			})
+			continue
+		}
+		if !hasKind(raw[key], kind) {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: fmt.Sprintf("expected a %s, got %T", kind, raw[key]),
+				Fix:     fmt.Sprintf("Set '%s' to a %s value", key, kind),
+			})
+		}
+	}
+
+	if key, _ := raw["gemini_api_key"].(string); key == "" {
+		issues = append(issues, Issue{
+			Key:     "gemini_api_key",
+			Problem: "API key is not set",
+			Fix:     "Run 'ted settings' and enter your Gemini API key",
+		})
+	}
+
+	if model, ok := raw["model"].(string); ok && model == "" {
+		issues = append(issues, Issue{
+			Key:     "model",
+			Problem: "model is empty",
+			Fix:     fmt.Sprintf("Run 'ted settings' to pick a model, or remove the key to use %s", DefaultModel),
+		})
+	}
+
+	if temp, ok := toFloat(raw["temperature"]); ok && (temp < 0 || temp > 1) {
+		issues = append(issues, Issue{
+			Key:     "temperature",
+			Problem: fmt.Sprintf("temperature %.2f is outside 0.0-1.0", temp),
+			Fix:     "Run 'ted settings' and enter a temperature between 0.0 and 1.0",
+		})
+	}
+
+	if count, ok := raw["ask_count"].(int); ok && (count < 1 || count > MaxAskCount) {
+		issues = append(issues, Issue{
+			Key:     "ask_count",
+			Problem: fmt.Sprintf("ask_count %d is outside 1-%d", count, MaxAskCount),
+			Fix:     fmt.Sprintf("Set 'ask_count' to a number between 1 and %d", MaxAskCount),
+		})
+	}
+
+	if shell, ok := raw["shell"].(string); ok && shell != "" {
+		if _, err := exec.LookPath(shell); err != nil {
+			issues = append(issues, Issue{
+				Key:     "shell",
+				Problem: fmt.Sprintf("shell %q not found, commands will run in sh", shell),
+				Fix:     "Install the shell, set 'shell' to its full path, or remove the key to use $SHELL",
+			})
+		}
+	}
+
+	if limit, ok := raw["tool_output_limit"].(int); ok && limit < 1 {
+		issues = append(issues, Issue{
+			Key:     "tool_output_limit",
+			Problem: fmt.Sprintf("tool_output_limit %d must be positive", limit),
+			Fix:     fmt.Sprintf("Set 'tool_output_limit' to a number of bytes, or remove the key to use %d", tools.DefaultOutputLimit),
+		})
+	}
+
+	if !validDuration(raw["cache_ttl"]) {
+		issues = append(issues, Issue{
+			Key:     "cache_ttl",
+			Problem: fmt.Sprintf("cache_ttl %v is not a valid duration", raw["cache_ttl"]),
+			Fix:     "Set 'cache_ttl' to a duration such as \"24h\", or 0 to disable the cache",
+		})
+	}
+
+	if !validDuration(raw["request_timeout"]) {
+		issues = append(issues, Issue{
+			Key:     "request_timeout",
+			Problem: fmt.Sprintf("request_timeout %v is not a valid duration", raw["request_timeout"]),
+			Fix:     "Set 'request_timeout' to a duration such as \"60s\", or 0 for no timeout",
+		})
+	}
+
+	if retries, ok := raw["max_retries"].(int); ok && retries < 0 {
+		issues = append(issues, Issue{
+			Key:     "max_retries",
+			Problem: fmt.Sprintf("max_retries %d is negative", retries),
+			Fix:     "Set 'max_retries' to 0 or more",
+		})
+	}
+
+	if limit, ok := toFloat(raw["monthly_limit"]); ok && limit < 0 {
+		issues = append(issues, Issue{
+			Key:     "monthly_limit",
+			Problem: fmt.Sprintf("monthly_limit %.2f is negative", limit),
+			Fix:     "Set 'monthly_limit' to an amount in USD, or 0 to disable the warning",
+		})
+	}
+
+	if path, ok := raw["audit_log"].(string); ok && path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/") {
+		issues = append(issues, Issue{
+			Key:     "audit_log",
+			Problem: fmt.Sprintf("audit_log %q is not an absolute path", path),
+			Fix:     "Set 'audit_log' to an absolute path or one starting with ~/, or remove the key to use ~/.ted/audit.log",
+		})
+	}
+
+	if !validDuration(raw["exec_timeout"]) {
+		issues = append(issues, Issue{
+			Key:     "exec_timeout",
+			Problem: fmt.Sprintf("exec_timeout %v is not a valid duration", raw["exec_timeout"]),
+			Fix:     "Set 'exec_timeout' to a duration such as \"10m\", or 0 for no timeout",
+		})
+	}
+
+	if limit, ok := raw["capture_limit"].(int); ok && limit < 0 {
+		issues = append(issues, Issue{
+			Key:     "capture_limit",
+			Problem: fmt.Sprintf("capture_limit %d is negative", limit),
+			Fix:     "Set 'capture_limit' to a number of bytes, or 0 to turn capture off",
+		})
+	}
+
+	issues = append(issues, validatePrompts(raw["prompts"])...)
+	issues = append(issues, validateTools(raw["tools"])...)
+	issues = append(issues, validatePricing(raw["pricing"])...)
+	issues = append(issues, validateLimits(raw["limits"])...)
+
+	return issues, nil
+}
+
+func validatePrompts(value any) []Issue {
+	overrides, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []Issue
+	for _, name := range names {
+		key := "prompts." + name
+		if !slices.Contains(prompts.Names, name) {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: "unknown prompt template",
+				Fix:     fmt.Sprintf("Use one of: %s", strings.Join(prompts.Names, ", ")),
+			})
+			continue
+		}
+		text, ok := overrides[name].(string)
+		if !ok {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: fmt.Sprintf("expected a string, got %T", overrides[name]),
+				Fix:     fmt.Sprintf("Set '%s' to the template text", key),
+			})
+			continue
+		}
+		if err := prompts.Check(text); err != nil {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: fmt.Sprintf("invalid template: %v", err),
+				Fix:     fmt.Sprintf("Fix the template or remove it and run 'ted prompt show %s' to see the default", name),
+			})
+		}
+	}
+	return issues
+}
+
+func validateTools(value any) []Issue {
+	names, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	var issues []Issue
+	for _, name := range names {
+		if name, ok := name.(string); ok && slices.Contains(tools.Names, name) {
+			continue
+		}
+		issues = append(issues, Issue{
+			Key:     "tools",
+			Problem: fmt.Sprintf("unknown tool %v", name),
+			Fix:     fmt.Sprintf("Use any of: %s", strings.Join(tools.Names, ", ")),
+		})
+	}
+	return issues
+}
+
+func validatePricing(value any) []Issue {
+	pricing, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	models := make([]string, 0, len(pricing))
+	for model := range pricing {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	var issues []Issue
+	for _, model := range models {
+		key := "pricing." + model
+		fix := fmt.Sprintf("Set '%s' to a map with 'input' and 'output' prices in USD per million tokens", key)
+
+		fields, ok := pricing[model].(map[string]any)
+		if !ok {
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: fmt.Sprintf("expected a map, got %T", pricing[model]),
+				Fix:     fix,
+			})
+			continue
+		}
+		for _, field := range []string{"input", "output"} {
+			if price, ok := toFloat(fields[field]); !ok || price < 0 {
+				issues = append(issues, Issue{
+					Key:     key + "." + field,
+					Problem: "price must be a number of 0 or more",
+					Fix:     fix,
+				})
+			}
+		}
+		for field := range fields {
+			if field != "input" && field != "output" {
+				issues = append(issues, Issue{
+					Key:     key + "." + field,
+					Problem: "unknown key",
+					Fix:     fix,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func hasKind(value any, kind valueKind) bool {
+	switch kind {
+	case kindString:
+		_, ok := value.(string)
+		return ok
+	case kindNumber:
+		_, ok := toFloat(value)
+		return ok
+	case kindInt:
+		_, ok := value.(int)
+		return ok
+	case kindMap:
+		_, ok := value.(map[string]any)
+		return ok
+	case kindList:
+		_, ok := value.([]any)
+		return ok
+	case kindDuration:
+		switch value.(type) {
+		case string, int:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// validDuration reports whether value, if set, is a duration of 0 or more.
+// A bare number has no unit, so the only one accepted is 0.
+func validDuration(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case int:
+		return v == 0
+	case string:
+		d, err := time.ParseDuration(v)
+		return err == nil && d >= 0
+	}
+	// The kind check reports other types.
+	return true
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func validateLimits(value any) []Issue {
+	limits, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []Issue
+	for _, name := range names {
+		key := "limits." + name
+		switch name {
+		case "cpu":
+			text, ok := limits[name].(string)
+			if d, err := time.ParseDuration(text); !ok || err != nil || d < 0 {
+				issues = append(issues, Issue{
+					Key:     key,
+					Problem: fmt.Sprintf("%v is not a valid duration", limits[name]),
+					Fix:     fmt.Sprintf("Set '%s' to CPU time such as \"60s\", or remove it", key),
+				})
+			}
+		case "memory_mb", "file_size_mb":
+			if n, ok := limits[name].(int); !ok || n < 0 {
+				issues = append(issues, Issue{
+					Key:     key,
+					Problem: fmt.Sprintf("expected a number of megabytes, got %v", limits[name]),
+					Fix:     fmt.Sprintf("Set '%s' to 0 or more, or remove it", key),
+				})
+			}
+		default:
+			issues = append(issues, Issue{
+				Key:     key,
+				Problem: "unknown limit",
+				Fix:     "Use cpu, memory_mb or file_size_mb",
+			})
+		}
+	}
+	if len(limits) > 0 && !shell.LimitsSupported {
+		issues = append(issues, Issue{
+			Key:     "limits",
+			Problem: "resource limits are only supported on Linux and will be ignored",
+			Fix:     "Remove 'limits' on this system",
+		})
+	}
+	return issues
+}
@@ -0,0 +1,130 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Example is a query and the command that answered it, used as a few-shot
+// example in every request.
+type Example struct {
+	Query   string `json:"query"`
+	Command string `json:"command"`
+}
+
+const maxExamples = 20
+
+func GetExamplesPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ted", "examples.json"), nil
+}
+
+// Load returns the stored examples, oldest first.
+func Load() ([]Example, error) {
+	path, err := GetExamplesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+
+	var list []Example
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse examples: %w", err)
+	}
+
+	return list, nil
+}
+
+// Save replaces the stored examples.
+func Save(list []Example) error {
+	path, err := GetExamplesPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create examples directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add appends examples to the store, skipping duplicates and dropping the
+// oldest entries beyond the limit. It returns the number of examples added.
+func Add(newExamples ...Example) (int, error) {
+	list, err := Load()
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, example := range newExamples {
+		example.Query = strings.TrimSpace(example.Query)
+		example.Command = strings.TrimSpace(example.Command)
+		if example.Query == "" || example.Command == "" || contains(list, example) {
+			continue
+		}
+		list = append(list, example)
+		added++
+	}
+
+	if len(list) > maxExamples {
+		list = list[len(list)-maxExamples:]
+	}
+
+	return added, Save(list)
+}
+
+// Remove deletes the example at index.
+func Remove(index int) error {
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+
+	if index < 0 || index >= len(list) {
+		return fmt.Errorf("no example at position %d", index+1)
+	}
+
+	return Save(append(list[:index], list[index+1:]...))
+}
+
+// Clear deletes all stored examples.
+func Clear() error {
+	path, err := GetExamplesPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete examples: %w", err)
+	}
+	return nil
+}
+
+func contains(list []Example, example Example) bool {
+	for _, existing := range list {
+		if existing.Query == example.Query && existing.Command == example.Command {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+var chatSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"message": {
+			Type:        genai.TypeString,
+			Description: "The reply to the user",
+		},
+		"command": {
+			Type:        genai.TypeString,
+			Description: "A single executable command that helps, or an empty string if none is needed",
+		},
+	},
+	Required: []string{"message", "command"},
+}
+
+// Chat roles.
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+)
+
+// ChatTurn is one message in a chat conversation. Command is only set on
+// model turns that suggested a command.
+type ChatTurn struct {
+	Role    string `json:"role"`
+	Text    string `json:"text"`
+	Command string `json:"command,omitempty"`
+}
+
+// ChatResponse is the model's reply to a chat message.
+type ChatResponse struct {
+	Message string `json:"message"`
+	Command string `json:"command"`
+	Usage   Usage  `json:"-"`
+}
+
+// Chat is a multi-turn conversation that keeps earlier messages as context.
+type Chat struct {
+	client  *Client
+	session *genai.ChatSession
+}
+
+// StartChat starts a conversation, replaying history from an earlier session.
+func (c *Client) StartChat(history []ChatTurn) *Chat {
+	c.model.ResponseMIMEType = "application/json"
+	c.model.ResponseSchema = chatSchema
+
+	session := c.model.StartChat()
+	for _, turn := range history {
+		text := turn.Text
+		if turn.Role == RoleModel {
+			data, err := json.Marshal(ChatResponse{Message: turn.Text, Command: turn.Command})
+			if err != nil {
+				continue
+			}
+			text = string(data)
+		}
+		session.History = append(session.History, &genai.Content{
+			Role:  turn.Role,
+			Parts: []genai.Part{genai.Text(text)},
+		})
+	}
+
+	return &Chat{client: c, session: session}
+}
+
+// Send sends a message and parses the reply. An invalid reply is repaired
+// once; only the repaired reply is kept in the conversation. On failure the
+// message is dropped from the conversation so it can be retried.
+func (ch *Chat) Send(ctx context.Context, text string) (*ChatResponse, error) {
+	before := len(ch.session.History)
+
+	var response ChatResponse
+	validate := func() error {
+		if strings.TrimSpace(response.Message) == "" && strings.TrimSpace(response.Command) == "" {
+			return fmt.Errorf("message is empty")
+		}
+		return nil
+	}
+
+	resp, err := ch.send(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	usage := usageOf(resp)
+
+	if _, problem := parseResponse(resp, &response, validate); problem != nil {
+		resp, err := ch.send(ctx, repairPrompt(problem))
+		if err != nil {
+			ch.session.History = ch.session.History[:before]
+			return nil, fmt.Errorf("failed to repair invalid response (%v): %w", problem, err)
+		}
+		usage.add(usageOf(resp))
+
+		if _, err := parseResponse(resp, &response, validate); err != nil {
+			ch.session.History = ch.session.History[:before]
+			return nil, fmt.Errorf("invalid response from model: %w", err)
+		}
+
+		// Keep the user's message and the repaired reply only.
+		last := ch.session.History[len(ch.session.History)-1]
+		ch.session.History = append(ch.session.History[:before+1], last)
+	}
+	response.Usage = usage
+
+	return &response, nil
+}
+
+// send sends text in the session, leaving the history as it was if the
+// request fails.
+func (ch *Chat) send(ctx context.Context, text string) (*genai.GenerateContentResponse, error) {
+	before := len(ch.session.History)
+	ch.client.logger.Debug("model prompt", "prompt", text)
+
+	resp, err := ch.client.generate(ctx, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		// SendMessage adds the message to the history even when it fails.
+		ch.session.History = ch.session.History[:before]
+		return ch.session.SendMessage(ctx, genai.Text(text))
+	})
+	if err != nil {
+		ch.session.History = ch.session.History[:before]
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+	return resp, nil
+}
+
+// History returns the conversation so far.
+func (ch *Chat) History() []ChatTurn {
+	var turns []ChatTurn
+	for _, content := range ch.session.History {
+		var text strings.Builder
+		for _, part := range content.Parts {
+			if t, ok := part.(genai.Text); ok {
+				text.WriteString(string(t))
+			}
+		}
+
+		turn := ChatTurn{Role: content.Role, Text: text.String()}
+		if content.Role == RoleModel {
+			var response ChatResponse
+			if err := json.Unmarshal([]byte(turn.Text), &response); err == nil {
+				turn.Text = response.Message
+				turn.Command = response.Command
+			}
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
@@ -2,10 +2,16 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/logging"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -41,32 +47,161 @@ var (
 							Type:        genai.TypeString,
 							Description: "Description of what the command does",
 						},
+						"risk_level": riskLevelSchema,
+						"required_tools": {
+							Type:        genai.TypeArray,
+							Items:       &genai.Schema{Type: genai.TypeString},
+							Description: "Programs the command needs that may not be installed by default",
+						},
+						"modifies_state": {
+							Type:        genai.TypeBoolean,
+							Description: "Whether the command changes files, processes, packages or system settings",
+						},
 					},
-					Required: []string{"command", "description"},
+					Required: []string{"command", "description", "risk_level", "required_tools", "modifies_state"},
 				},
 			},
 		},
 		Required: []string{"commands"},
 	}
+
+	riskLevelSchema = &genai.Schema{
+		Type:        genai.TypeString,
+		Enum:        []string{RiskLow, RiskMedium, RiskHigh},
+		Description: "How risky this part is: low for read-only, medium for recoverable changes, high for destructive or irreversible effects",
+	}
+
+	explainSchema = &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"summary": {
+				Type:        genai.TypeString,
+				Description: "One or two sentences describing what the whole command does",
+			},
+			"risk_level": riskLevelSchema,
+			"stages": {
+				Type:        genai.TypeArray,
+				Description: "Each stage of the pipeline or command list, in order",
+				Items: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"command": {
+							Type:        genai.TypeString,
+							Description: "The exact text of this stage",
+						},
+						"explanation": {
+							Type:        genai.TypeString,
+							Description: "What this stage does",
+						},
+						"risk_level": riskLevelSchema,
+						"risk_reason": {
+							Type:        genai.TypeString,
+							Description: "Why this stage is risky, or an empty string if it is low risk",
+						},
+						"arguments": {
+							Type:        genai.TypeArray,
+							Description: "Each flag, option, argument or redirection in this stage",
+							Items: &genai.Schema{
+								Type: genai.TypeObject,
+								Properties: map[string]*genai.Schema{
+									"text": {
+										Type:        genai.TypeString,
+										Description: "The exact text of the flag or argument",
+									},
+									"explanation": {
+										Type:        genai.TypeString,
+										Description: "What it means",
+									},
+									"risk_level": riskLevelSchema,
+								},
+								Required: []string{"text", "explanation", "risk_level"},
+							},
+						},
+					},
+					Required: []string{"command", "explanation", "risk_level", "risk_reason", "arguments"},
+				},
+			},
+		},
+		Required: []string{"summary", "risk_level", "stages"},
+	}
 )
 
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
+	client     *genai.Client
+	model      *genai.GenerativeModel
+	modelName  string
+	timeout    time.Duration
+	maxRetries int
+	onUsage    func(Usage)
+	logger     *slog.Logger
 }
 
 type AgentResponse struct {
-	Command     string `json:"command"`
-	Explanation string `json:"explanation"`
+	Command     string     `json:"command"`
+	Explanation string     `json:"explanation"`
+	ToolCalls   []ToolCall `json:"tool_calls,omitempty"`
+	Usage       Usage      `json:"-"`
 }
 
 type AskResponse struct {
 	Commands []CommandOption `json:"commands"`
+	Usage    Usage           `json:"-"`
+}
+
+// ExplainResponse is a structured breakdown of an existing shell command.
+type ExplainResponse struct {
+	Summary   string         `json:"summary"`
+	RiskLevel string         `json:"risk_level"`
+	Stages    []ExplainStage `json:"stages"`
+	Usage     Usage          `json:"-"`
+}
+
+// ExplainStage describes one stage of a pipeline or command list.
+type ExplainStage struct {
+	Command     string            `json:"command"`
+	Explanation string            `json:"explanation"`
+	RiskLevel   string            `json:"risk_level"`
+	RiskReason  string            `json:"risk_reason"`
+	Arguments   []ExplainArgument `json:"arguments"`
+}
+
+// ExplainArgument describes a flag, argument or redirection within a stage.
+type ExplainArgument struct {
+	Text        string `json:"text"`
+	Explanation string `json:"explanation"`
+	RiskLevel   string `json:"risk_level"`
+}
+
+// Usage holds the token counts reported for a request.
+type Usage struct {
+	PromptTokens   int32 `json:"prompt_tokens"`
+	ResponseTokens int32 `json:"response_tokens"`
+	TotalTokens    int32 `json:"total_tokens"`
 }
 
+// Risk levels reported for command options.
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
 type CommandOption struct {
-	Command     string `json:"command"`
-	Description string `json:"description"`
+	Command       string   `json:"command"`
+	Description   string   `json:"description"`
+	RiskLevel     string   `json:"risk_level"`
+	RequiredTools []string `json:"required_tools"`
+	ModifiesState bool     `json:"modifies_state"`
+}
+
+// ModelInfo describes a model that can be used for content generation.
+type ModelInfo struct {
+	Name             string   `json:"name"`
+	DisplayName      string   `json:"display_name"`
+	Description      string   `json:"description"`
+	InputTokenLimit  int32    `json:"input_token_limit"`
+	OutputTokenLimit int32    `json:"output_token_limit"`
+	Capabilities     []string `json:"capabilities"`
 }
 
 func NewClient(apiKey, modelName string, temperature float32) (*Client, error) {
@@ -80,8 +215,12 @@ func NewClient(apiKey, modelName string, temperature float32) (*Client, error) {
 	model.SetTemperature(temperature)
 
 	return &Client{
-		client: client,
-		model:  model,
+		client:     client,
+		model:      model,
+		modelName:  modelName,
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		logger:     logging.Logger(),
 	}, nil
 }
 
@@ -89,56 +228,148 @@ func (c *Client) Close() {
 	c.client.Close()
 }
 
-func (c *Client) GenerateAgentCommand(ctx context.Context, query string) (*AgentResponse, error) {
-	c.model.ResponseMIMEType = "application/json"
-	c.model.ResponseSchema = agentSchema
-
-	prompt := fmt.Sprintf(`You are a helpful command-line assistant. The user wants to accomplish the following task: "%s"
+// SetSystemInstruction sets the system instruction sent with every request.
+func (c *Client) SetSystemInstruction(text string) {
+	if text == "" {
+		c.model.SystemInstruction = nil
+		return
+	}
+	c.model.SystemInstruction = genai.NewUserContent(genai.Text(text))
+}
 
-Please respond with a JSON object containing the command and explanation.`, query)
+// Probe sends a minimal request to verify that the configured model responds.
+func (c *Client) Probe(ctx context.Context) error {
+	model := c.client.GenerativeModel(c.modelName)
+	model.SetMaxOutputTokens(1)
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	_, err := c.generate(ctx, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		return model.GenerateContent(ctx, genai.Text("ping"))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return fmt.Errorf("failed to generate content: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
+	return nil
+}
+
+// ListModels returns the models available to the API key that support
+// content generation.
+func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+
+	it := c.client.ListModels(ctx)
+	for {
+		info, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list models: %w", err)
+		}
+
+		if !slices.Contains(info.SupportedGenerationMethods, "generateContent") {
+			continue
+		}
+
+		models = append(models, ModelInfo{
+			Name:             strings.TrimPrefix(info.Name, "models/"),
+			DisplayName:      info.DisplayName,
+			Description:      info.Description,
+			InputTokenLimit:  info.InputTokenLimit,
+			OutputTokenLimit: info.OutputTokenLimit,
+			Capabilities:     info.SupportedGenerationMethods,
+		})
 	}
 
-	content := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	return models, nil
+}
 
+// GenerateAgentCommand sends the rendered agent prompt and parses the single
+// command it returns.
+func (c *Client) GenerateAgentCommand(ctx context.Context, prompt string) (*AgentResponse, error) {
 	var response AgentResponse
-	if err := json.Unmarshal([]byte(content), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	usage, err := c.generateStructured(ctx, prompt, agentSchema, &response, response.validate)
+	if err != nil {
+		return nil, err
 	}
+	response.Usage = usage
 
 	return &response, nil
 }
 
-func (c *Client) GenerateAskCommands(ctx context.Context, question string) (*AskResponse, error) {
-	c.model.ResponseMIMEType = "application/json"
-	c.model.ResponseSchema = askSchema
-
-	prompt := fmt.Sprintf(`The user is asking: "%s"
+// GenerateAskCommands sends the rendered ask prompt and parses the command
+// options it returns, expecting at least count of them.
+func (c *Client) GenerateAskCommands(ctx context.Context, prompt string, count int) (*AskResponse, error) {
+	var response AskResponse
+	usage, err := c.generateStructured(ctx, prompt, askSchema, &response, func() error {
+		if len(response.Commands) < count {
+			return fmt.Errorf("expected %d command options, got %d", count, len(response.Commands))
+		}
+		for i, option := range response.Commands {
+			what := fmt.Sprintf("command option %d", i+1)
+			if err := checkCommand(what, option.Command); err != nil {
+				return err
+			}
+			if err := checkRiskLevel(what, option.RiskLevel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.Usage = usage
 
-Please provide exactly 3 different command-line commands that help answer this question. Return a JSON object with a "commands" array.`, question)
+	return &response, nil
+}
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+// ExplainCommand sends the rendered explain prompt and parses the breakdown
+// of the command it returns.
+func (c *Client) ExplainCommand(ctx context.Context, prompt string) (*ExplainResponse, error) {
+	var response ExplainResponse
+	usage, err := c.generateStructured(ctx, prompt, explainSchema, &response, func() error {
+		if strings.TrimSpace(response.Summary) == "" {
+			return fmt.Errorf("summary is empty")
+		}
+		if err := checkRiskLevel("the command", response.RiskLevel); err != nil {
+			return err
+		}
+		if len(response.Stages) == 0 {
+			return fmt.Errorf("stages is empty")
+		}
+		for i, stage := range response.Stages {
+			if err := checkRiskLevel(fmt.Sprintf("stage %d", i+1), stage.RiskLevel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return nil, err
 	}
+	response.Usage = usage
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
+	return &response, nil
+}
 
-	content := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+func (r *AgentResponse) validate() error {
+	return checkCommand("command", r.Command)
+}
 
-	var response AskResponse
-	if err := json.Unmarshal([]byte(content), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
-	}
+func (u *Usage) add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.ResponseTokens += other.ResponseTokens
+	u.TotalTokens += other.TotalTokens
+}
 
-	return &response, nil
+func usageOf(resp *genai.GenerateContentResponse) Usage {
+	if resp.UsageMetadata == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:   resp.UsageMetadata.PromptTokenCount,
+		ResponseTokens: resp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:    resp.UsageMetadata.TotalTokenCount,
+	}
 }
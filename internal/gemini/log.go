@@ -0,0 +1,77 @@
+package gemini
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// SetLogger sets the logger that records prompts, raw output, latency and
+// retries. By default the client uses logging.Logger.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// rawOutput returns the text and function calls of the first candidate in
+// resp, as the model sent them.
+func rawOutput(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, part := range resp.Candidates[0].Content.Parts {
+		switch p := part.(type) {
+		case genai.Text:
+			parts = append(parts, string(p))
+		case genai.FunctionCall:
+			args, _ := json.Marshal(p.Args)
+			parts = append(parts, p.Name+string(args))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// finishReason returns why the model stopped, e.g. "Stop" or "MaxTokens".
+func finishReason(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(resp.Candidates[0].FinishReason.String(), "FinishReason")
+}
+
+// schemaJSON renders schema as JSON schema text for the log.
+func schemaJSON(schema *genai.Schema) string {
+	data, _ := json.Marshal(schemaMap(schema))
+	return string(data)
+}
+
+func schemaMap(schema *genai.Schema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+
+	m := map[string]any{"type": strings.ToLower(strings.TrimPrefix(schema.Type.String(), "Type"))}
+	if schema.Description != "" {
+		m["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		m["enum"] = schema.Enum
+	}
+	if schema.Items != nil {
+		m["items"] = schemaMap(schema.Items)
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]any, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = schemaMap(property)
+		}
+		m["properties"] = properties
+	}
+	if len(schema.Required) > 0 {
+		m["required"] = schema.Required
+	}
+	return m
+}
@@ -0,0 +1,149 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// generateStructured sends prompt with schema as the response schema and
+// decodes the reply into v, which must be a pointer to a struct. validate
+// checks the decoded value. If the reply can't be parsed or fails
+// validation, the model is asked once to repair it, quoting the problem,
+// before giving up. It returns the tokens used by all requests.
+func (c *Client) generateStructured(ctx context.Context, prompt string, schema *genai.Schema, v any, validate func() error) (Usage, error) {
+	c.model.ResponseMIMEType = "application/json"
+	c.model.ResponseSchema = schema
+
+	c.logger.Debug("model prompt", "prompt", prompt, "schema", schemaJSON(schema))
+
+	var usage Usage
+	resp, err := c.generate(ctx, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		return c.model.GenerateContent(ctx, genai.Text(prompt))
+	})
+	if err != nil {
+		return usage, fmt.Errorf("failed to generate content: %w", err)
+	}
+	usage.add(usageOf(resp))
+
+	text, problem := parseResponse(resp, v, validate)
+	if problem == nil {
+		return usage, nil
+	}
+
+	c.logger.Info("repairing invalid response", "problem", problem)
+
+	session := c.model.StartChat()
+	session.History = []*genai.Content{
+		genai.NewUserContent(genai.Text(prompt)),
+		{Role: RoleModel, Parts: []genai.Part{genai.Text(orPlaceholder(text))}},
+	}
+	before := len(session.History)
+
+	resp, err = c.generate(ctx, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		session.History = session.History[:before]
+		return session.SendMessage(ctx, genai.Text(repairPrompt(problem)))
+	})
+	if err != nil {
+		return usage, fmt.Errorf("failed to repair invalid response (%v): %w", problem, err)
+	}
+	usage.add(usageOf(resp))
+
+	if _, err := parseResponse(resp, v, validate); err != nil {
+		return usage, fmt.Errorf("invalid response from model: %w", err)
+	}
+	return usage, nil
+}
+
+// parseResponse decodes the text of resp into v and validates it. It returns
+// the text so a failed response can be quoted back to the model.
+func parseResponse(resp *genai.GenerateContentResponse, v any, validate func() error) (string, error) {
+	text := responseText(resp)
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("the response was empty")
+	}
+
+	// Clear anything left over from an earlier attempt.
+	reflect.ValueOf(v).Elem().SetZero()
+	if err := json.Unmarshal([]byte(extractJSON(text)), v); err != nil {
+		return text, fmt.Errorf("the response is not valid JSON: %w", err)
+	}
+	if validate != nil {
+		if err := validate(); err != nil {
+			return text, err
+		}
+	}
+	return text, nil
+}
+
+// responseText joins the text parts of the first candidate in resp.
+func responseText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			b.WriteString(string(text))
+		}
+	}
+	return b.String()
+}
+
+// extractJSON returns the JSON object in text, dropping a Markdown code fence
+// and any prose around it.
+func extractJSON(text string) string {
+	text = strings.TrimSpace(text)
+
+	if start := strings.Index(text, "```"); start >= 0 {
+		fenced := text[start+3:]
+		if newline := strings.IndexByte(fenced, '\n'); newline >= 0 {
+			fenced = fenced[newline+1:]
+		}
+		if end := strings.Index(fenced, "```"); end >= 0 {
+			fenced = fenced[:end]
+		}
+		text = strings.TrimSpace(fenced)
+	}
+
+	start := strings.IndexByte(text, '{')
+	end := strings.LastIndexByte(text, '}')
+	if start >= 0 && end > start {
+		return text[start : end+1]
+	}
+	return text
+}
+
+func repairPrompt(problem error) string {
+	return fmt.Sprintf("Your previous response could not be used: %v. Respond again with only a JSON object that matches the required schema and fixes this problem.", problem)
+}
+
+func orPlaceholder(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "(empty response)"
+	}
+	return text
+}
+
+// checkCommand returns an error naming what if command is blank.
+func checkCommand(what, command string) error {
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("%s is empty", what)
+	}
+	return nil
+}
+
+// checkRiskLevel returns an error naming what if level isn't a known risk level.
+func checkRiskLevel(what, level string) error {
+	if !slices.Contains([]string{RiskLow, RiskMedium, RiskHigh}, level) {
+		return fmt.Errorf("%s has risk_level %q, expected low, medium or high", what, level)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+var planSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary": {
+			Type:        genai.TypeString,
+			Description: "One sentence describing what the plan accomplishes",
+		},
+		"steps": {
+			Type:        genai.TypeArray,
+			Description: "The commands to run, in order",
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"command": {
+						Type:        genai.TypeString,
+						Description: "A single executable command for this step",
+					},
+					"explanation": {
+						Type:        genai.TypeString,
+						Description: "Brief explanation of what this step does",
+					},
+					"safe": {
+						Type:        genai.TypeBoolean,
+						Description: "Whether this step is read-only or trivially reversible, so it can run without asking",
+					},
+				},
+				Required: []string{"command", "explanation", "safe"},
+			},
+		},
+	},
+	Required: []string{"summary", "steps"},
+}
+
+// PlanResponse is an ordered list of commands that together accomplish a task.
+type PlanResponse struct {
+	Summary string     `json:"summary"`
+	Steps   []PlanStep `json:"steps"`
+	Usage   Usage      `json:"-"`
+}
+
+// PlanStep is one command in a plan. Safe steps can be run without
+// confirmation when the user chooses to run the whole plan.
+type PlanStep struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+	Safe        bool   `json:"safe"`
+}
+
+// GeneratePlan sends the rendered plan prompt and parses the steps it returns.
+func (c *Client) GeneratePlan(ctx context.Context, prompt string) (*PlanResponse, error) {
+	var response PlanResponse
+	usage, err := c.generateStructured(ctx, prompt, planSchema, &response, func() error {
+		if len(response.Steps) == 0 {
+			return fmt.Errorf("the plan has no steps")
+		}
+		for i, step := range response.Steps {
+			if err := checkCommand(fmt.Sprintf("the command of step %d", i+1), step.Command); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.Usage = usage
+
+	return &response, nil
+}
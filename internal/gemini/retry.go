@@ -0,0 +1,208 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// DefaultTimeout bounds each request to the API.
+	DefaultTimeout = 60 * time.Second
+
+	// DefaultMaxRetries is how many times a failed request is retried.
+	DefaultMaxRetries = 3
+
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+
+	// maxRetryAfter is the longest server-requested wait ted will sit
+	// through; a longer one usually means a daily quota is used up.
+	maxRetryAfter = 60 * time.Second
+)
+
+// Errors returned for failures the user can act on. They are wrapped with
+// details, so check for them with errors.Is.
+var (
+	ErrRateLimited   = errors.New("rate limit or quota exceeded")
+	ErrInvalidAPIKey = errors.New("the Gemini API key was rejected")
+	ErrBlocked       = errors.New("blocked by the model's safety filters")
+	ErrTimeout       = errors.New("request timed out")
+)
+
+// SetTimeout sets how long each request may take before it is cancelled.
+// Zero means no timeout.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetMaxRetries sets how many times a request that failed with a retryable
+// error (rate limiting, a server error or a network problem) is retried.
+func (c *Client) SetMaxRetries(retries int) {
+	c.maxRetries = max(0, retries)
+}
+
+// SetUsageHandler sets a function called with the token usage of every
+// successful request, including repairs and tool-calling rounds.
+func (c *Client) SetUsageHandler(handler func(Usage)) {
+	c.onUsage = handler
+}
+
+// generate calls send with a timeout, retrying retryable failures with
+// exponential backoff and jitter, or after the delay the server asks for.
+func (c *Client) generate(ctx context.Context, send func(context.Context) (*genai.GenerateContentResponse, error)) (*genai.GenerateContentResponse, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := c.attempt(ctx, send)
+		latency := time.Since(start).Round(time.Millisecond)
+		if err == nil {
+			usage := usageOf(resp)
+			c.logger.Info("model request", "model", c.modelName, "attempt", attempt+1, "latency", latency,
+				"prompt_tokens", usage.PromptTokens, "response_tokens", usage.ResponseTokens, "finish_reason", finishReason(resp))
+			c.logger.Debug("model output", "output", rawOutput(resp))
+			if c.onUsage != nil {
+				c.onUsage(usage)
+			}
+			return resp, nil
+		}
+
+		wait, retryable := retryDelay(err, attempt)
+		if !retryable || attempt >= c.maxRetries || ctx.Err() != nil {
+			c.logger.Info("model request failed", "model", c.modelName, "attempt", attempt+1, "latency", latency, "error", err)
+			return nil, describeError(err, c.modelName)
+		}
+		c.logger.Info("retrying model request", "model", c.modelName, "attempt", attempt+1, "latency", latency,
+			"wait", wait.Round(time.Millisecond), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, send func(context.Context) (*genai.GenerateContentResponse, error)) (*genai.GenerateContentResponse, error) {
+	if c.timeout <= 0 {
+		return send(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := send(attemptCtx)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w after %s", ErrTimeout, c.timeout)
+	}
+	return resp, err
+}
+
+// retryDelay reports whether err is worth retrying and how long to wait
+// before the given retry attempt.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code != http.StatusTooManyRequests && apiErr.Code < 500 {
+			return 0, false
+		}
+		if wait, ok := serverRetryDelay(apiErr); ok {
+			return wait, wait <= maxRetryAfter
+		}
+		return backoff(attempt), true
+	}
+
+	if errors.Is(err, ErrTimeout) || isNetworkError(err) {
+		return backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff returns a random wait of up to initialBackoff*2^attempt, capped at
+// maxBackoff ("full jitter"). The shift is capped well past maxBackoff so a
+// high max_retries can't overflow it.
+func backoff(attempt int) time.Duration {
+	limit := min(initialBackoff<<min(attempt, 20), maxBackoff)
+	return time.Duration(rand.Int64N(int64(limit))) + 100*time.Millisecond
+}
+
+// serverRetryDelay returns the delay requested by the Retry-After header or
+// the RetryInfo detail of the error.
+func serverRetryDelay(apiErr *googleapi.Error) (time.Duration, bool) {
+	if value := apiErr.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return time.Until(at), true
+		}
+	}
+
+	for _, detail := range apiErr.Details {
+		info, ok := detail.(map[string]any)
+		if !ok || !strings.HasSuffix(fmt.Sprint(info["@type"]), "RetryInfo") {
+			continue
+		}
+		if delay, ok := info["retryDelay"].(string); ok {
+			if wait, err := time.ParseDuration(delay); err == nil {
+				return wait, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// describeError turns API errors the user can act on into clear messages.
+func describeError(err error, model string) error {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		reason := "unknown reason"
+		switch {
+		case blocked.PromptFeedback != nil:
+			reason = "prompt: " + strings.TrimPrefix(blocked.PromptFeedback.BlockReason.String(), "BlockReason")
+		case blocked.Candidate != nil:
+			reason = "response: " + strings.TrimPrefix(blocked.Candidate.FinishReason.String(), "FinishReason")
+		}
+		return fmt.Errorf("%w (%s). Try rephrasing the request", ErrBlocked, reason)
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		detail := "for " + model
+		if wait, ok := serverRetryDelay(apiErr); ok {
+			detail += fmt.Sprintf("; try again in %s", wait.Round(time.Second))
+		}
+		return fmt.Errorf("%w %s. Check your limits at https://ai.google.dev/gemini-api/docs/rate-limits or pick another model with 'ted settings'", ErrRateLimited, detail)
+	case apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden ||
+		strings.Contains(apiErr.Message, "API key not valid") || strings.Contains(apiErr.Body, "API_KEY_INVALID"):
+		return fmt.Errorf("%w: %s. Run 'ted settings' to update it", ErrInvalidAPIKey, strings.TrimSuffix(apiErr.Message, "."))
+	case apiErr.Code == http.StatusNotFound:
+		return fmt.Errorf("model %s was not found: %s. Run 'ted models' to see available models", model, strings.TrimSuffix(apiErr.Message, "."))
+	case apiErr.Code >= 500:
+		return fmt.Errorf("the Gemini API is unavailable (%d: %s). Try again later", apiErr.Code, apiErr.Message)
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package gemini
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffStaysInRange(t *testing.T) {
+	for _, attempt := range []int{0, 1, 5, 30, 34, 63, 64, 100, 1000} {
+		for range 100 {
+			wait := backoff(attempt)
+			if wait < 100*time.Millisecond || wait > maxBackoff+100*time.Millisecond {
+				t.Fatalf("backoff(%d) = %s, want between 100ms and %s", attempt, wait, maxBackoff+100*time.Millisecond)
+			}
+		}
+	}
+}
+
+func TestBackoffGrows(t *testing.T) {
+	// The first attempt waits at most initialBackoff plus the fixed 100ms.
+	for range 100 {
+		if wait := backoff(0); wait > initialBackoff+100*time.Millisecond {
+			t.Fatalf("backoff(0) = %s, want at most %s", wait, initialBackoff+100*time.Millisecond)
+		}
+	}
+}
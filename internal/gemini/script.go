@@ -0,0 +1,62 @@
+package gemini
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+var scriptSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"script": {
+			Type:        genai.TypeString,
+			Description: "The complete script, starting with its shebang line",
+		},
+		"explanation": {
+			Type:        genai.TypeString,
+			Description: "Brief explanation of what the script does and how to run it",
+		},
+	},
+	Required: []string{"script", "explanation"},
+}
+
+// ScriptResponse is a complete script generated from a description.
+type ScriptResponse struct {
+	Script      string `json:"script"`
+	Explanation string `json:"explanation"`
+	Usage       Usage  `json:"-"`
+}
+
+// GenerateScript sends the rendered script prompt and parses the script it
+// returns.
+func (c *Client) GenerateScript(ctx context.Context, prompt string) (*ScriptResponse, error) {
+	var response ScriptResponse
+	usage, err := c.generateStructured(ctx, prompt, scriptSchema, &response, func() error {
+		return checkCommand("script", response.Script)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.Script = stripFence(response.Script)
+	response.Usage = usage
+
+	return &response, nil
+}
+
+// stripFence removes a Markdown code fence wrapped around text, which models
+// sometimes add even inside JSON.
+func stripFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text + "\n"
+	}
+
+	lines := strings.Split(text, "\n")
+	lines = lines[1:]
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "```" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
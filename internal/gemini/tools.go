@@ -0,0 +1,183 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+const (
+	// maxToolRounds limits how many times the model may call tools before it
+	// has to submit a command.
+	maxToolRounds = 6
+
+	submitToolName = "submit_command"
+)
+
+// Tool describes a local function the model may call while working out a
+// command.
+type Tool struct {
+	Name        string
+	Description string
+	Params      []ToolParam
+}
+
+// ToolParam is an argument of a Tool. Type is a JSON schema type name,
+// "string" or "integer".
+type ToolParam struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// ToolCall is a tool invocation requested by the model.
+type ToolCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+// ToolRunner executes a tool call and returns its output for the model.
+type ToolRunner func(ctx context.Context, call ToolCall) string
+
+// GenerateAgentCommandWithTools sends the rendered agent prompt and lets the
+// model call tools, run by run, before it submits a single command. Tool
+// calls are made in order and recorded in the response.
+func (c *Client) GenerateAgentCommandWithTools(ctx context.Context, prompt string, tools []Tool, run ToolRunner) (*AgentResponse, error) {
+	// Function calling can't be combined with a JSON response schema, so the
+	// final answer is itself a function call and the model is made to always
+	// call one.
+	model := *c.model
+	model.ResponseMIMEType = ""
+	model.ResponseSchema = nil
+	model.Tools = []*genai.Tool{{FunctionDeclarations: declarations(tools)}}
+	model.ToolConfig = &genai.ToolConfig{
+		FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingAny},
+	}
+
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	c.logger.Debug("model prompt", "prompt", prompt, "tools", names)
+
+	var response AgentResponse
+	repaired := false
+	session := model.StartChat()
+	parts := []genai.Part{genai.Text(prompt)}
+
+	for round := 0; round < maxToolRounds; round++ {
+		if round == maxToolRounds-1 {
+			model.ToolConfig.FunctionCallingConfig.AllowedFunctionNames = []string{submitToolName}
+		}
+
+		before := len(session.History)
+		resp, err := c.generate(ctx, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+			// SendMessage adds the message to the history even when it fails.
+			session.History = session.History[:before]
+			return session.SendMessage(ctx, parts...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate content: %w", err)
+		}
+		response.Usage.add(usageOf(resp))
+
+		if len(resp.Candidates) == 0 {
+			return nil, fmt.Errorf("no response generated")
+		}
+		calls := resp.Candidates[0].FunctionCalls()
+		if len(calls) == 0 {
+			return nil, fmt.Errorf("model did not call a tool or submit a command")
+		}
+
+		parts = nil
+		for _, fc := range calls {
+			if fc.Name == submitToolName {
+				problem := decodeSubmission(fc.Args, &response)
+				if problem == nil {
+					return &response, nil
+				}
+				if repaired {
+					return nil, fmt.Errorf("invalid response from model: %w", problem)
+				}
+				// Let the model submit again once, telling it what was wrong.
+				c.logger.Info("repairing invalid response", "problem", problem)
+				repaired = true
+				parts = append(parts, genai.FunctionResponse{
+					Name:     fc.Name,
+					Response: map[string]any{"error": repairPrompt(problem)},
+				})
+				continue
+			}
+
+			call := ToolCall{Name: fc.Name, Args: fc.Args}
+			response.ToolCalls = append(response.ToolCalls, call)
+			output := run(ctx, call)
+			args, _ := json.Marshal(call.Args)
+			c.logger.Debug("tool call", "name", call.Name, "args", string(args), "output", output)
+			parts = append(parts, genai.FunctionResponse{
+				Name:     fc.Name,
+				Response: map[string]any{"output": output},
+			})
+		}
+	}
+
+	return nil, fmt.Errorf("model did not submit a command after %d rounds of tool calls", maxToolRounds)
+}
+
+// decodeSubmission copies the command and explanation submitted in args to
+// response and validates them.
+func decodeSubmission(args map[string]any, response *AgentResponse) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("the submitted arguments are not valid JSON: %w", err)
+	}
+
+	var submitted AgentResponse
+	if err := json.Unmarshal(data, &submitted); err != nil {
+		return fmt.Errorf("the submitted arguments don't match the schema: %w", err)
+	}
+	if err := submitted.validate(); err != nil {
+		return err
+	}
+
+	response.Command = submitted.Command
+	response.Explanation = submitted.Explanation
+	return nil
+}
+
+// declarations converts tools to function declarations, adding the function
+// the model calls to submit its answer.
+func declarations(tools []Tool) []*genai.FunctionDeclaration {
+	decls := make([]*genai.FunctionDeclaration, 0, len(tools)+1)
+	for _, tool := range tools {
+		params := &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{},
+		}
+		for _, param := range tool.Params {
+			kind := genai.TypeString
+			if param.Type == "integer" {
+				kind = genai.TypeInteger
+			}
+			params.Properties[param.Name] = &genai.Schema{Type: kind, Description: param.Description}
+			if param.Required {
+				params.Required = append(params.Required, param.Name)
+			}
+		}
+
+		decl := &genai.FunctionDeclaration{Name: tool.Name, Description: tool.Description}
+		if len(tool.Params) > 0 {
+			decl.Parameters = params
+		}
+		decls = append(decls, decl)
+	}
+
+	return append(decls, &genai.FunctionDeclaration{
+		Name:        submitToolName,
+		Description: "Submit the final command for the user's task. Call this once you know enough.",
+		Parameters:  agentSchema,
+	})
+}
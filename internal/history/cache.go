@@ -0,0 +1,161 @@
+package history
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const cacheBucketName = "cache"
+
+// CacheEntry is a cached model response. Response holds the response encoded
+// as JSON.
+type CacheEntry struct {
+	Mode     string
+	Query    string
+	Model    string
+	Created  time.Time
+	Expires  time.Time
+	Hits     int
+	Response []byte
+}
+
+// CacheStats summarizes the response cache.
+type CacheStats struct {
+	Entries int
+	Expired int
+	Hits    int
+	Bytes   int
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// GetCached returns the unexpired entry stored under key and counts the hit,
+// or nil if there is none.
+func (h *History) GetCached(key string) (*CacheEntry, error) {
+	var entry *CacheEntry
+
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(cacheBucketName))
+
+		data := bucket.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+
+		var cached CacheEntry
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cached); err != nil {
+			return bucket.Delete([]byte(key))
+		}
+		if time.Now().After(cached.Expires) {
+			return bucket.Delete([]byte(key))
+		}
+
+		cached.Hits++
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(cached); err != nil {
+			return fmt.Errorf("failed to encode cache entry: %w", err)
+		}
+		entry = &cached
+		return bucket.Put([]byte(key), buf.Bytes())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	return entry, nil
+}
+
+// PutCached stores entry under key, replacing any earlier entry, and removes
+// expired entries.
+func (h *History) PutCached(key string, entry CacheEntry) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(cacheBucketName))
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode cache entry: %w", err)
+		}
+		if err := bucket.Put([]byte(key), buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to store cache entry: %w", err)
+		}
+
+		return pruneCache(bucket)
+	})
+}
+
+// GetCacheStats counts the cached responses.
+func (h *History) GetCacheStats() (CacheStats, error) {
+	var stats CacheStats
+	now := time.Now()
+
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(cacheBucketName)).ForEach(func(k, v []byte) error {
+			var entry CacheEntry
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&entry); err != nil {
+				return nil
+			}
+
+			stats.Entries++
+			stats.Bytes += len(k) + len(v)
+			stats.Hits += entry.Hits
+			if now.After(entry.Expires) {
+				stats.Expired++
+			}
+			if stats.Oldest.IsZero() || entry.Created.Before(stats.Oldest) {
+				stats.Oldest = entry.Created
+			}
+			if entry.Created.After(stats.Newest) {
+				stats.Newest = entry.Created
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return CacheStats{}, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ClearCache removes every cached response.
+func (h *History) ClearCache() error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(cacheBucketName)); err != nil {
+			return fmt.Errorf("failed to delete cache bucket: %w", err)
+		}
+
+		if _, err := tx.CreateBucket([]byte(cacheBucketName)); err != nil {
+			return fmt.Errorf("failed to recreate cache bucket: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// pruneCache deletes expired entries from bucket.
+func pruneCache(bucket *bbolt.Bucket) error {
+	now := time.Now()
+
+	var expired [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var entry CacheEntry
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&entry); err != nil || now.After(entry.Expires) {
+			expired = append(expired, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range expired {
+		if err := bucket.Delete(k); err != nil {
+			return fmt.Errorf("failed to delete expired cache entry: %w", err)
+		}
+	}
+	return nil
+}
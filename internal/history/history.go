@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/jadenpxrk/ted/internal/redact"
+
 	"go.etcd.io/bbolt"
 )
 
@@ -19,15 +23,90 @@ type Entry struct {
 	Query     string
 	Response  string
 	Selected  *string
+
+	// Steps records each step of a plan and how it ended. It is empty for
+	// other entries.
+	Steps []Step
+
+	// Script holds the script generated by 'ted script'.
+	Script string
+
+	// Tokens records the model and token counts of the request that
+	// produced the entry.
+	Tokens Tokens
+
+	// Run records how the selected command ended when ted ran it. It is nil
+	// if the command wasn't run.
+	Run *Run
+}
+
+// Run is the outcome of running an entry's selected command. Output holds
+// the end of its combined output when capture_limit is set, with secrets
+// redacted, and Truncated reports whether earlier output was dropped.
+type Run struct {
+	ExitCode  int
+	Duration  time.Duration
+	TimedOut  bool
+	Output    string
+	Truncated bool
+}
+
+// Step is one command of a plan entry.
+type Step struct {
+	Command     string
+	Explanation string
+	Edited      bool
+	Status      string
+	ExitCode    int
+}
+
+// Step statuses.
+const (
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"
+	StepNotRun    = "not run"
+)
+
+// Session is a saved chat conversation.
+type Session struct {
+	ID       uint64
+	Created  time.Time
+	Updated  time.Time
+	Title    string
+	Messages []Message
+}
+
+// Message is one turn of a chat session. Command is set on model turns that
+// suggested a command.
+type Message struct {
+	Role    string
+	Text    string
+	Command string
 }
 
 type History struct {
 	db *bbolt.DB
 }
 
+// The database is opened once per process and shared between handles, since
+// bbolt's file lock would otherwise make concurrent handles in one process,
+// such as the requests of 'ted serve', wait for each other and time out.
+var (
+	openMu  sync.Mutex
+	openDB  *bbolt.DB
+	openRef int
+)
+
+// ErrLocked is returned when the history database is held open by another process.
+var ErrLocked = errors.New("history database is locked by another process")
+
 const (
 	bucketName = "history"
 	maxEntries = 5
+
+	sessionsBucketName = "sessions"
+	maxSessions        = 20
 )
 
 func GetHistoryPath() (string, error) {
@@ -38,7 +117,25 @@ func GetHistoryPath() (string, error) {
 	return filepath.Join(homeDir, ".ted", "history.db"), nil
 }
 
+// Load opens the history database, or shares the handle already open in this
+// process. Each History must be closed.
 func Load() (*History, error) {
+	openMu.Lock()
+	defer openMu.Unlock()
+
+	if openDB == nil {
+		db, err := open()
+		if err != nil {
+			return nil, err
+		}
+		openDB = db
+	}
+	openRef++
+
+	return &History{db: openDB}, nil
+}
+
+func open() (*bbolt.DB, error) {
 	dbPath, err := GetHistoryPath()
 	if err != nil {
 		return nil, err
@@ -48,31 +145,121 @@ func Load() (*History, error) {
 		return nil, fmt.Errorf("failed to create history directory: %w", err)
 	}
 
-	db, err := bbolt.Open(dbPath, 0644, &bbolt.Options{Timeout: 1 * time.Second})
+	// The database holds queries and command output, so only the user may
+	// read it. Databases created with a wider mode are narrowed.
+	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{Timeout: 1 * time.Second})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open history database: %w", err)
 	}
+	if err := os.Chmod(dbPath, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict history database: %w", err)
+	}
 
 	err = db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
-		return err
+		for _, name := range []string{bucketName, sessionsBucketName, cacheBucketName, usageBucketName} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create history bucket: %w", err)
 	}
 
-	return &History{db: db}, nil
+	return db, nil
 }
 
+// Verify opens the history database read-only and checks its consistency.
+// It returns ErrLocked if another process holds the database lock.
+func Verify() error {
+	dbPath, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return ErrLocked
+		}
+		return fmt.Errorf("failed to open history database: %w", err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bbolt.Tx) error {
+		var errs []error
+		for err := range tx.Check() {
+			errs = append(errs, err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("history database is corrupted: %w", errors.Join(errs...))
+		}
+		return nil
+	})
+}
+
+// Close releases the handle, closing the database once no other handle in
+// this process uses it.
 func (h *History) Close() error {
-	if h.db != nil {
-		return h.db.Close()
+	if h.db == nil {
+		return nil
 	}
-	return nil
+	h.db = nil
+
+	openMu.Lock()
+	defer openMu.Unlock()
+
+	openRef--
+	if openRef > 0 {
+		return nil
+	}
+	db := openDB
+	openDB = nil
+	return db.Close()
+}
+
+func (h *History) AddEntry(command, query, response string, selected *string, run *Run, tokens Tokens) error {
+	if run != nil && run.Output != "" {
+		redacted := *run
+		redacted.Output = redact.String(run.Output)
+		run = &redacted
+	}
+	return h.addEntry(Entry{
+		Command:  command,
+		Query:    query,
+		Response: response,
+		Selected: selected,
+		Run:      run,
+		Tokens:   tokens,
+	})
+}
+
+// AddPlan records a plan and the outcome of each of its steps.
+func (h *History) AddPlan(query, summary string, steps []Step, tokens Tokens) error {
+	return h.addEntry(Entry{
+		Command:  "plan",
+		Query:    query,
+		Response: summary,
+		Steps:    steps,
+		Tokens:   tokens,
+	})
+}
+
+// AddScript records a generated script and where it was written.
+func (h *History) AddScript(query, path, script string, tokens Tokens) error {
+	return h.addEntry(Entry{
+		Command:  "script",
+		Query:    query,
+		Response: path,
+		Script:   script,
+		Tokens:   tokens,
+	})
 }
 
-func (h *History) AddEntry(command, query, response string, selected *string) error {
+func (h *History) addEntry(entry Entry) error {
 	return h.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketName))
 
@@ -81,14 +268,8 @@ func (h *History) AddEntry(command, query, response string, selected *string) er
 			return fmt.Errorf("failed to generate entry ID: %w", err)
 		}
 
-		entry := Entry{
-			ID:        id,
-			Timestamp: time.Now(),
-			Command:   command,
-			Query:     query,
-			Response:  response,
-			Selected:  selected,
-		}
+		entry.ID = id
+		entry.Timestamp = time.Now()
 
 		var buf bytes.Buffer
 		encoder := gob.NewEncoder(&buf)
@@ -170,7 +351,90 @@ func (h *History) Clear() error {
 	})
 }
 
+// SaveSession stores a chat session, assigning it an ID if it has none.
+func (h *History) SaveSession(session *Session) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(sessionsBucketName))
+
+		now := time.Now()
+		if session.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to generate session ID: %w", err)
+			}
+			session.ID = id
+			session.Created = now
+		}
+		session.Updated = now
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(session); err != nil {
+			return fmt.Errorf("failed to encode session: %w", err)
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, session.ID)
+
+		if err := bucket.Put(key, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to store session: %w", err)
+		}
+
+		return h.trimBucket(bucket, maxSessions)
+	})
+}
+
+// GetSession returns the chat session with the given ID.
+func (h *History) GetSession(id uint64) (*Session, error) {
+	var session *Session
+
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, id)
+
+		data := tx.Bucket([]byte(sessionsBucketName)).Get(key)
+		if data == nil {
+			return fmt.Errorf("no chat session with ID %d", id)
+		}
+
+		session = &Session{}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetSessions returns all saved chat sessions, most recent first.
+func (h *History) GetSessions() ([]Session, error) {
+	var sessions []Session
+
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket([]byte(sessionsBucketName)).Cursor()
+
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var session Session
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&session); err != nil {
+				continue
+			}
+			sessions = append(sessions, session)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
 func (h *History) trimToMaxEntries(bucket *bbolt.Bucket) error {
+	return h.trimBucket(bucket, maxEntries)
+}
+
+// trimBucket deletes the oldest keys in bucket beyond limit.
+func (h *History) trimBucket(bucket *bbolt.Bucket, limit int) error {
 	cursor := bucket.Cursor()
 
 	count := 0
@@ -178,11 +442,11 @@ func (h *History) trimToMaxEntries(bucket *bbolt.Bucket) error {
 		count++
 	}
 
-	if count <= maxEntries {
+	if count <= limit {
 		return nil
 	}
 
-	toDelete := count - maxEntries
+	toDelete := count - limit
 	cursor = bucket.Cursor()
 
 	for k, _ := cursor.First(); k != nil && toDelete > 0; k, _ = cursor.Next() {
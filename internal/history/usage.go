@@ -0,0 +1,116 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	usageBucketName = "usage"
+
+	// usageRetention is how long usage records are kept. It covers a full
+	// year so that monthly totals can be compared.
+	usageRetention = 400 * 24 * time.Hour
+)
+
+// Tokens is the token usage of a single model request.
+type Tokens struct {
+	Model          string
+	PromptTokens   int32
+	ResponseTokens int32
+}
+
+// UsageRecord is one model request in the usage log. Unlike history entries
+// the log is not capped, so it covers every request made.
+type UsageRecord struct {
+	Time time.Time
+	Mode string
+	Tokens
+}
+
+// AddUsage appends a record to the usage log and removes records older than
+// the retention period.
+func (h *History) AddUsage(record UsageRecord) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(usageBucketName))
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to generate usage ID: %w", err)
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+			return fmt.Errorf("failed to encode usage record: %w", err)
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, id)
+
+		if err := bucket.Put(key, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to store usage record: %w", err)
+		}
+
+		return pruneUsage(bucket, time.Now().Add(-usageRetention))
+	})
+}
+
+// GetUsage returns the usage records made at or after since, oldest first.
+func (h *History) GetUsage(since time.Time) ([]UsageRecord, error) {
+	var records []UsageRecord
+
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket([]byte(usageBucketName)).Cursor()
+
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var record UsageRecord
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&record); err != nil {
+				continue
+			}
+			if record.Time.Before(since) {
+				continue
+			}
+			records = append(records, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve usage: %w", err)
+	}
+
+	return records, nil
+}
+
+// ClearUsage deletes the usage log.
+func (h *History) ClearUsage() error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(usageBucketName)); err != nil {
+			return fmt.Errorf("failed to delete usage bucket: %w", err)
+		}
+		if _, err := tx.CreateBucket([]byte(usageBucketName)); err != nil {
+			return fmt.Errorf("failed to recreate usage bucket: %w", err)
+		}
+		return nil
+	})
+}
+
+// pruneUsage deletes records made before cutoff. Records are keyed in the
+// order they were added, so it stops at the first newer one.
+func pruneUsage(bucket *bbolt.Bucket, cutoff time.Time) error {
+	cursor := bucket.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.First() {
+		var record UsageRecord
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&record); err == nil && !record.Time.Before(cutoff) {
+			return nil
+		}
+		if err := bucket.Delete(k); err != nil {
+			return fmt.Errorf("failed to delete old usage record: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Languages lists the script languages that can be checked.
+var Languages = []string{"bash", "sh", "zsh", "python"}
+
+const checkTimeout = 10 * time.Second
+
+// pythonSyntaxCheck parses a script from stdin without running it.
+const pythonSyntaxCheck = `import ast, sys
+try:
+    ast.parse(sys.stdin.read())
+except SyntaxError as e:
+    print(f"line {e.lineno}: {e.msg}")
+    sys.exit(1)`
+
+// Result holds the problems found in a script. Errors mean the script won't
+// run; warnings are worth a look but don't stop it from being saved.
+type Result struct {
+	Errors   []string
+	Warnings []string
+}
+
+// OK reports whether no errors were found.
+func (r *Result) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Check validates script locally: a syntax check with the language's own
+// interpreter (dash, when installed, for sh), shellcheck for bash and sh
+// scripts when it is installed, and a few checks of its own for the shebang
+// and strict mode.
+func Check(ctx context.Context, language, script string) (*Result, error) {
+	if !slices.Contains(Languages, language) {
+		return nil, fmt.Errorf("unsupported language %q, use one of: %s", language, strings.Join(Languages, ", "))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	result := &Result{}
+	checkHeader(result, language, script)
+
+	if language == "python" {
+		checkSyntax(ctx, result, "python3", script, "-c", pythonSyntaxCheck)
+		return result, nil
+	}
+
+	if language == "sh" {
+		checkPOSIX(ctx, result, script)
+		return result, nil
+	}
+
+	checkSyntax(ctx, result, language, script, "-n")
+	if language == "bash" {
+		shellcheck(ctx, result, language, script)
+	}
+	return result, nil
+}
+
+// checkPOSIX checks an sh script. sh is often bash, which accepts bash-only
+// syntax such as arrays and [[ even in POSIX mode, so the stricter dash
+// parses the script when it is installed, and shellcheck looks for the
+// bashisms a parser can't see. Without shellcheck that is reported, so a
+// clean result isn't taken to mean the script is portable.
+func checkPOSIX(ctx context.Context, result *Result, script string) {
+	interpreter := "sh"
+	if _, err := exec.LookPath("dash"); err == nil {
+		interpreter = "dash"
+	}
+	checkSyntax(ctx, result, interpreter, script, "-n")
+
+	if _, err := exec.LookPath("shellcheck"); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("POSIX compliance not checked: %s -n accepts some bash-only syntax, install shellcheck to check for it", interpreter))
+		return
+	}
+	shellcheck(ctx, result, "sh", script)
+}
+
+func checkHeader(result *Result, language, script string) {
+	first, _, _ := strings.Cut(script, "\n")
+	if !strings.HasPrefix(first, "#!") {
+		result.Warnings = append(result.Warnings, "missing shebang line")
+	}
+
+	switch language {
+	case "bash", "zsh":
+		if !strings.Contains(script, "set -euo pipefail") && !strings.Contains(script, "set -eo pipefail") {
+			result.Warnings = append(result.Warnings, "missing 'set -euo pipefail'")
+		}
+	case "sh":
+		if !strings.Contains(script, "set -e") {
+			result.Warnings = append(result.Warnings, "missing 'set -e'")
+		}
+	}
+}
+
+// checkSyntax runs the interpreter with args on script and records its
+// complaints as errors.
+func checkSyntax(ctx context.Context, result *Result, interpreter, script string, args ...string) {
+	if _, err := exec.LookPath(interpreter); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("syntax not checked: %s is not installed", interpreter))
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, interpreter, args...)
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return
+	}
+
+	message := strings.TrimSpace(string(output))
+	if message == "" {
+		message = err.Error()
+	}
+	result.Errors = append(result.Errors, splitLines(message)...)
+}
+
+func shellcheck(ctx context.Context, result *Result, language, script string) {
+	if _, err := exec.LookPath("shellcheck"); err != nil {
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, "shellcheck", "--format=gcc", "--shell="+language, "-")
+	cmd.Stdin = strings.NewReader(script)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	// shellcheck exits non-zero whenever it has findings, so only its
+	// output matters.
+	_ = cmd.Run()
+
+	for _, line := range splitLines(stdout.String()) {
+		// Lines look like "-:3:7: warning: message [SC2086]".
+		result.Warnings = append(result.Warnings, strings.TrimPrefix(line, "-:"))
+	}
+}
+
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,129 @@
+// Package logging provides the structured log written with --verbose, --debug
+// or TED_LOG. It is off by default. Every string in a record, including error
+// messages, passes through redact before it is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/redact"
+)
+
+// EnvVar selects the log level and destination, e.g. "debug" or
+// "info,file".
+const EnvVar = "TED_LOG"
+
+// FileName is the log file written to the log directory when TED_LOG
+// includes "file".
+const FileName = "ted.log"
+
+// logger is replaced by Setup; until then nothing is logged.
+var logger = slog.New(discardHandler{})
+
+// Logger returns the logger configured by Setup.
+func Logger() *slog.Logger {
+	return logger
+}
+
+// Settings say what to log and where.
+type Settings struct {
+	// Enabled turns logging on at Level.
+	Enabled bool
+	Level   slog.Level
+
+	// ToFile writes JSON records to a rotating file instead of text records
+	// to stderr.
+	ToFile bool
+}
+
+// ParseEnv parses the value of TED_LOG: a comma-separated list of a level
+// (info or debug) and a destination (stderr or file). Either may be left
+// out; the defaults are info and stderr. An empty value or "off" disables
+// logging.
+func ParseEnv(value string) (Settings, error) {
+	var settings Settings
+	value = strings.TrimSpace(value)
+	if value == "" || value == "off" {
+		return settings, nil
+	}
+
+	settings.Enabled = true
+	settings.Level = slog.LevelInfo
+	for _, field := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "info":
+			settings.Level = slog.LevelInfo
+		case "debug":
+			settings.Level = slog.LevelDebug
+		case "stderr":
+			settings.ToFile = false
+		case "file":
+			settings.ToFile = true
+		default:
+			return Settings{}, fmt.Errorf("invalid %s value %q: expected info or debug, optionally with stderr or file, e.g. \"debug,file\"", EnvVar, value)
+		}
+	}
+	return settings, nil
+}
+
+// Setup configures the logger. File logs are written to FileName in dir,
+// which is created if needed. The file stays open for the life of the
+// process.
+func Setup(settings Settings, dir string) error {
+	if !settings.Enabled {
+		logger = slog.New(discardHandler{})
+		return nil
+	}
+
+	opts := &slog.HandlerOptions{Level: settings.Level, ReplaceAttr: redactAttr}
+	if !settings.ToFile {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, opts))
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := openRotating(filepath.Join(dir, FileName))
+	if err != nil {
+		return err
+	}
+	logger = slog.New(slog.NewJSONHandler(file, opts))
+	return nil
+}
+
+// redactAttr removes secrets from string and error values. Prompts, tool
+// output and provider errors can all contain them.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redact.String(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(redact.String(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(redact.String(v.String()))
+		case []string:
+			redacted := make([]string, len(v))
+			for i, s := range v {
+				redacted[i] = redact.String(s)
+			}
+			a.Value = slog.AnyValue(redacted)
+		}
+	}
+	return a
+}
+
+// discardHandler drops every record without formatting it.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// maxFileSize is the size at which the log file is rotated.
+	maxFileSize = 5 << 20
+
+	// maxBackups is how many rotated files are kept, as ted.log.1 (newest)
+	// to ted.log.3.
+	maxBackups = 3
+)
+
+// rotatingFile appends to a log file, moving it aside once it grows past
+// maxFileSize. Several ted processes may append at once; a rotation racing
+// with another process at worst splits its records across two files.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+func openRotating(path string) (*rotatingFile, error) {
+	f := &rotatingFile{path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > maxFileSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts ted.log to ted.log.1, ted.log.1 to ted.log.2 and so on,
+// dropping the oldest, and starts a new file.
+func (f *rotatingFile) rotate() error {
+	f.file.Close()
+	for i := maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	_ = os.Rename(f.path, f.path+".1")
+	return f.open()
+}
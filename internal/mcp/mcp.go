@@ -0,0 +1,307 @@
+// Package mcp implements the tool-serving subset of the Model Context
+// Protocol: JSON-RPC 2.0 messages exchanged as newline-delimited JSON, as
+// used by the stdio transport.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// ProtocolVersion is the newest protocol revision the server speaks. Clients
+// asking for one of supportedVersions get that revision instead.
+const ProtocolVersion = "2025-06-18"
+
+var supportedVersions = []string{"2024-11-05", "2025-03-26", ProtocolVersion}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// maxMessageSize caps the size of a single incoming message.
+const maxMessageSize = 4 << 20
+
+// Tool is a tool the server offers. Handler receives the raw arguments of a
+// call; an error it returns is reported to the model as a failed call.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema Schema
+	Handler     func(ctx context.Context, args json.RawMessage) (*Result, error)
+}
+
+// Schema is the JSON schema of a tool's arguments.
+type Schema struct {
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Property describes one argument of a tool.
+type Property struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Minimum     *int   `json:"minimum,omitempty"`
+	Maximum     *int   `json:"maximum,omitempty"`
+}
+
+// Result is the outcome of a tool call. Text is shown to the model, and
+// Structured, if set, is sent alongside it as structured content.
+type Result struct {
+	Text       string
+	Structured any
+	IsError    bool
+}
+
+// Server answers MCP requests for a set of tools.
+type Server struct {
+	name         string
+	version      string
+	instructions string
+	tools        []Tool
+}
+
+// NewServer creates a server that introduces itself with name and version.
+func NewServer(name, version string) *Server {
+	return &Server{name: name, version: version}
+}
+
+// SetInstructions sets the usage hints sent to the client on initialization.
+func (s *Server) SetInstructions(text string) {
+	s.instructions = text
+}
+
+// AddTool registers a tool.
+func (s *Server) AddTool(tool Tool) {
+	s.tools = append(s.tools, tool)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError"`
+}
+
+// Serve reads requests from r and writes responses to w until r is exhausted
+// or ctx is cancelled. Requests are handled concurrently, and a call can be
+// cancelled with notifications/cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu  sync.Mutex
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		inflight = map[string]context.CancelFunc{}
+	)
+	encoder := json.NewEncoder(w)
+	write := func(resp response) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = encoder.Encode(resp)
+	}
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			write(errorResponse(json.RawMessage("null"), codeParseError, fmt.Sprintf("parse error: %v", err)))
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			id := req.ID
+			if id == nil {
+				id = json.RawMessage("null")
+			}
+			write(errorResponse(id, codeInvalidRequest, "invalid request"))
+			continue
+		}
+
+		// Notifications have no ID and get no response.
+		if req.ID == nil {
+			if req.Method == "notifications/cancelled" {
+				var params struct {
+					RequestID json.RawMessage `json:"requestId"`
+				}
+				if json.Unmarshal(req.Params, &params) == nil {
+					mu.Lock()
+					if cancelCall, ok := inflight[string(params.RequestID)]; ok {
+						cancelCall()
+					}
+					mu.Unlock()
+				}
+			}
+			continue
+		}
+
+		callCtx, cancelCall := context.WithCancel(ctx)
+		key := string(req.ID)
+		mu.Lock()
+		inflight[key] = cancelCall
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+				cancelCall()
+			}()
+
+			result, err := s.handle(callCtx, req)
+			// A cancelled request must not be answered.
+			if callCtx.Err() != nil && ctx.Err() == nil {
+				return
+			}
+			if err != nil {
+				rpcErr, ok := err.(*rpcError)
+				if !ok {
+					rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+				}
+				write(response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+				return
+			}
+			write(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handle(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]bool{"listChanged": false},
+		},
+		"serverInfo": map[string]string{
+			"name":    s.name,
+			"version": s.version,
+		},
+		"instructions": s.instructions,
+	}, nil
+}
+
+func (s *Server) listTools() any {
+	type toolInfo struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		InputSchema Schema `json:"inputSchema"`
+	}
+
+	tools := make([]toolInfo, len(s.tools))
+	for i, tool := range s.tools {
+		tools[i] = toolInfo{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema}
+	}
+	return map[string]any{"tools": tools}
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	index := slices.IndexFunc(s.tools, func(tool Tool) bool { return tool.Name == p.Name })
+	if index < 0 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", p.Name)}
+	}
+	if len(p.Arguments) == 0 || string(p.Arguments) == "null" {
+		p.Arguments = json.RawMessage("{}")
+	}
+
+	result, err := s.tools[index].Handler(ctx, p.Arguments)
+	if err != nil {
+		return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return callResult{
+		Content:           []content{{Type: "text", Text: result.Text}},
+		StructuredContent: result.Structured,
+		IsError:           result.IsError,
+	}, nil
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/mcp/mcptest"
+)
+
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StructuredContent map[string]any `json:"structuredContent"`
+	IsError           bool           `json:"isError"`
+}
+
+func newTestServer() *Server {
+	s := NewServer("test", "1.2.3")
+	s.SetInstructions("Use with care.")
+	s.AddTool(Tool{
+		Name:        "echo",
+		Description: "Echo the text back",
+		InputSchema: Schema{
+			Type:       "object",
+			Properties: map[string]Property{"text": {Type: "string", Description: "Text to echo"}},
+			Required:   []string{"text"},
+		},
+		Handler: func(ctx context.Context, args json.RawMessage) (*Result, error) {
+			var p struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &p); err != nil {
+				return nil, err
+			}
+			if p.Text == "" {
+				return nil, errors.New("text is required")
+			}
+			return &Result{Text: p.Text, Structured: map[string]string{"echo": p.Text}}, nil
+		},
+	})
+	return s
+}
+
+func TestInitializeNegotiatesVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"2024-11-05", "2024-11-05"},
+		{"2025-03-26", "2025-03-26"},
+		{ProtocolVersion, ProtocolVersion},
+		{"1999-01-01", ProtocolVersion},
+		{"", ProtocolVersion},
+	}
+
+	client := mcptest.Start(t, newTestServer().Serve)
+	for i, tt := range tests {
+		resp := client.Call(i+1, "initialize", fmt.Sprintf(`{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test","version":"0"}}`, tt.requested))
+		result := mcptest.DecodeResult[struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			Capabilities    struct {
+				Tools *struct{} `json:"tools"`
+			} `json:"capabilities"`
+			ServerInfo struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"serverInfo"`
+			Instructions string `json:"instructions"`
+		}](t, resp)
+
+		if result.ProtocolVersion != tt.want {
+			t.Errorf("initialize with %q: protocolVersion = %q, want %q", tt.requested, result.ProtocolVersion, tt.want)
+		}
+		if result.Capabilities.Tools == nil {
+			t.Errorf("initialize with %q: tools capability missing", tt.requested)
+		}
+		if result.ServerInfo.Name != "test" || result.ServerInfo.Version != "1.2.3" {
+			t.Errorf("initialize with %q: serverInfo = %+v", tt.requested, result.ServerInfo)
+		}
+		if result.Instructions != "Use with care." {
+			t.Errorf("initialize with %q: instructions = %q", tt.requested, result.Instructions)
+		}
+	}
+}
+
+func TestToolsList(t *testing.T) {
+	client := mcptest.Start(t, newTestServer().Serve)
+
+	result := mcptest.DecodeResult[struct {
+		Tools []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			InputSchema Schema `json:"inputSchema"`
+		} `json:"tools"`
+	}](t, client.Call(1, "tools/list", ""))
+
+	if len(result.Tools) != 1 {
+		t.Fatalf("got %d tools, want 1", len(result.Tools))
+	}
+	tool := result.Tools[0]
+	if tool.Name != "echo" || tool.Description != "Echo the text back" {
+		t.Errorf("tool = %q %q", tool.Name, tool.Description)
+	}
+	if tool.InputSchema.Type != "object" || tool.InputSchema.Properties["text"].Type != "string" {
+		t.Errorf("inputSchema = %+v", tool.InputSchema)
+	}
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "text" {
+		t.Errorf("required = %v, want [text]", tool.InputSchema.Required)
+	}
+}
+
+func TestToolsCall(t *testing.T) {
+	client := mcptest.Start(t, newTestServer().Serve)
+
+	result := mcptest.DecodeResult[toolResult](t, client.Call(1, "tools/call", `{"name":"echo","arguments":{"text":"hello"}}`))
+	if result.IsError {
+		t.Errorf("isError = true for a successful call")
+	}
+	if len(result.Content) != 1 || result.Content[0].Type != "text" || result.Content[0].Text != "hello" {
+		t.Errorf("content = %+v, want one text item \"hello\"", result.Content)
+	}
+	if result.StructuredContent["echo"] != "hello" {
+		t.Errorf("structuredContent = %v", result.StructuredContent)
+	}
+
+	// A handler error is a failed call, not a protocol error.
+	result = mcptest.DecodeResult[toolResult](t, client.Call(2, "tools/call", `{"name":"echo","arguments":{"text":""}}`))
+	if !result.IsError {
+		t.Errorf("isError = false for a failed call")
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != "text is required" {
+		t.Errorf("content = %+v, want the handler's error", result.Content)
+	}
+
+	// Missing arguments reach the handler as an empty object.
+	result = mcptest.DecodeResult[toolResult](t, client.Call(3, "tools/call", `{"name":"echo"}`))
+	if !result.IsError || len(result.Content) != 1 || result.Content[0].Text != "text is required" {
+		t.Errorf("call without arguments = %+v", result)
+	}
+
+	resp := client.Call(4, "tools/call", `{"name":"missing","arguments":{}}`)
+	if resp.Error == nil || resp.Error.Code != codeInvalidParams {
+		t.Errorf("unknown tool: error = %+v, want code %d", resp.Error, codeInvalidParams)
+	}
+
+	resp = client.Call(5, "tools/call", `"echo"`)
+	if resp.Error == nil || resp.Error.Code != codeInvalidParams {
+		t.Errorf("invalid params: error = %+v, want code %d", resp.Error, codeInvalidParams)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	client := mcptest.Start(t, newTestServer().Serve)
+
+	resp := client.Call(1, "resources/list", "")
+	if resp.Error == nil || resp.Error.Code != codeMethodNotFound {
+		t.Fatalf("error = %+v, want code %d", resp.Error, codeMethodNotFound)
+	}
+	if resp.Result != nil {
+		t.Errorf("result = %s alongside an error", resp.Result)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	client := mcptest.Start(t, newTestServer().Serve)
+
+	client.Send(`{"jsonrpc":"2.0","id":1,`)
+	if resp := client.Next(); resp.Error == nil || resp.Error.Code != codeParseError || string(resp.ID) != "null" {
+		t.Errorf("malformed JSON: response = %+v, want parse error with null id", resp)
+	}
+
+	client.Send(`{"jsonrpc":"1.0","id":2,"method":"ping"}`)
+	if resp := client.Next(); resp.Error == nil || resp.Error.Code != codeInvalidRequest || string(resp.ID) != "2" {
+		t.Errorf("wrong version: response = %+v, want invalid request for id 2", resp)
+	}
+
+	// Notifications are never answered, so the next response is the ping's.
+	client.Send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	client.Call(3, "ping", "")
+}
+
+func TestCancelledSuppressesResponse(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	s := newTestServer()
+	s.AddTool(Tool{
+		Name:        "wait",
+		Description: "Wait until cancelled",
+		InputSchema: Schema{Type: "object"},
+		Handler: func(ctx context.Context, args json.RawMessage) (*Result, error) {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return &Result{Text: "finished"}, nil
+		},
+	})
+	client := mcptest.Start(t, s.Serve)
+
+	client.Send(`{"jsonrpc":"2.0","id":"slow","method":"tools/call","params":{"name":"wait","arguments":{}}}`)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not called")
+	}
+
+	client.Send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"slow","reason":"user"}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool's context was not cancelled")
+	}
+
+	// The cancelled call must not be answered, so the ping's response comes
+	// next.
+	client.Call(1, "ping", "")
+}
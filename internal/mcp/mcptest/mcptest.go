@@ -0,0 +1,124 @@
+// Package mcptest runs an MCP server over in-process pipes so tests can talk
+// to it as a client would.
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// timeout is how long Next waits for a response.
+const timeout = 5 * time.Second
+
+// Response is a JSON-RPC response from the server.
+type Response struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Error is the error of a failed request.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Client sends messages to a server and reads its responses.
+type Client struct {
+	t         testing.TB
+	in        *io.PipeWriter
+	responses chan Response
+}
+
+// Start runs serve, usually a Server's Serve method, until the test ends and
+// returns a client connected to it. The test fails if serve returns an
+// error.
+func Start(t testing.TB, serve func(ctx context.Context, in io.Reader, out io.Writer) error) *Client {
+	t.Helper()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+
+	responses := make(chan Response)
+	go func() {
+		defer close(responses)
+		decoder := json.NewDecoder(outR)
+		for {
+			var resp Response
+			if err := decoder.Decode(&resp); err != nil {
+				return
+			}
+			responses <- resp
+		}
+	}()
+
+	t.Cleanup(func() {
+		inW.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+	return &Client{t: t, in: inW, responses: responses}
+}
+
+// Send writes one message to the server.
+func (c *Client) Send(message string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, message+"\n"); err != nil {
+		c.t.Fatalf("failed to send %s: %v", message, err)
+	}
+}
+
+// Call sends a request with params, "{}" if empty, and returns the next
+// response, which must answer it.
+func (c *Client) Call(id int, method, params string) Response {
+	c.t.Helper()
+	if params == "" {
+		params = "{}"
+	}
+	c.Send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, id, method, params))
+
+	resp := c.Next()
+	if string(resp.ID) != fmt.Sprint(id) {
+		c.t.Fatalf("%s: response id = %s, want %d", method, resp.ID, id)
+	}
+	return resp
+}
+
+// Next returns the next response from the server.
+func (c *Client) Next() Response {
+	c.t.Helper()
+	select {
+	case resp, ok := <-c.responses:
+		if !ok {
+			c.t.Fatal("server closed its output")
+		}
+		return resp
+	case <-time.After(timeout):
+		c.t.Fatal("timed out waiting for a response")
+	}
+	return Response{}
+}
+
+// DecodeResult decodes the result of a successful response, failing the test
+// if it is an error.
+func DecodeResult[T any](t testing.TB, resp Response) T {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected error response: %d %s", resp.Error.Code, resp.Error.Message)
+	}
+	var result T
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("failed to decode result %s: %v", resp.Result, err)
+	}
+	return result
+}
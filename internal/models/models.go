@@ -0,0 +1,149 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/gemini"
+)
+
+// Source describes where a model list came from.
+type Source string
+
+const (
+	SourceLive    Source = "live"
+	SourceCache   Source = "cache"
+	SourceBuiltin Source = "built-in"
+)
+
+const cacheTTL = 24 * time.Hour
+
+// Builtin is the fallback model list used when the API can't be reached.
+var Builtin = []gemini.ModelInfo{
+	{
+		Name:             "gemini-2.0-flash",
+		DisplayName:      "Gemini 2.0 Flash",
+		InputTokenLimit:  1048576,
+		OutputTokenLimit: 8192,
+		Capabilities:     []string{"generateContent", "countTokens"},
+	},
+	{
+		Name:             "gemini-2.0-flash-lite",
+		DisplayName:      "Gemini 2.0 Flash-Lite",
+		InputTokenLimit:  1048576,
+		OutputTokenLimit: 8192,
+		Capabilities:     []string{"generateContent", "countTokens"},
+	},
+	{
+		Name:             "gemini-2.5-flash",
+		DisplayName:      "Gemini 2.5 Flash",
+		InputTokenLimit:  1048576,
+		OutputTokenLimit: 65536,
+		Capabilities:     []string{"generateContent", "countTokens"},
+	},
+	{
+		Name:             "gemini-2.5-pro",
+		DisplayName:      "Gemini 2.5 Pro",
+		InputTokenLimit:  1048576,
+		OutputTokenLimit: 65536,
+		Capabilities:     []string{"generateContent", "countTokens"},
+	},
+}
+
+type cacheFile struct {
+	FetchedAt time.Time          `json:"fetched_at"`
+	Models    []gemini.ModelInfo `json:"models"`
+}
+
+func GetCachePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ted", "models.json"), nil
+}
+
+// List returns the available models. A fresh on-disk cache is preferred unless
+// refresh is set; otherwise the API is queried and the cache updated. When no
+// API key is configured or the request fails, a stale cache or the built-in
+// list is returned together with the error that caused the fallback.
+func List(ctx context.Context, apiKey string, refresh bool) ([]gemini.ModelInfo, Source, error) {
+	cached, fetchedAt, cacheErr := readCache()
+	if cacheErr == nil && !refresh && time.Since(fetchedAt) < cacheTTL {
+		return cached, SourceCache, nil
+	}
+
+	if apiKey == "" {
+		return fallback(cached, cacheErr, fmt.Errorf("gemini API key not configured"))
+	}
+
+	client, err := gemini.NewClient(apiKey, "", 0)
+	if err != nil {
+		return fallback(cached, cacheErr, err)
+	}
+	defer client.Close()
+
+	live, err := client.ListModels(ctx)
+	if err != nil {
+		return fallback(cached, cacheErr, err)
+	}
+	if len(live) == 0 {
+		return fallback(cached, cacheErr, fmt.Errorf("no models returned"))
+	}
+
+	// The live list is still good without the cache, so a failure to save
+	// it isn't worth more than a warning.
+	if err := writeCache(live); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write model cache: %v\n", err)
+	}
+
+	return live, SourceLive, nil
+}
+
+func fallback(cached []gemini.ModelInfo, cacheErr, err error) ([]gemini.ModelInfo, Source, error) {
+	if cacheErr == nil && len(cached) > 0 {
+		return cached, SourceCache, err
+	}
+	return Builtin, SourceBuiltin, err
+}
+
+func readCache() ([]gemini.ModelInfo, time.Time, error) {
+	path, err := GetCachePath()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var cache cacheFile
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse model cache: %w", err)
+	}
+
+	return cache.Models, cache.FetchedAt, nil
+}
+
+func writeCache(list []gemini.ModelInfo) error {
+	path, err := GetCachePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cacheFile{FetchedAt: time.Now(), Models: list}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
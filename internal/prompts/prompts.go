@@ -0,0 +1,179 @@
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"text/template"
+
+	"github.com/jadenpxrk/ted/internal/examples"
+)
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+// Template names.
+const (
+	System  = "system"
+	Agent   = "agent"
+	Ask     = "ask"
+	Explain = "explain"
+	Fix     = "fix"
+	Chat    = "chat"
+	Plan    = "plan"
+	Script  = "script"
+)
+
+// Names lists every template that can be customized.
+var Names = []string{System, Agent, Ask, Explain, Fix, Chat, Plan, Script}
+
+// Source describes where a template was loaded from.
+type Source string
+
+const (
+	SourceConfig  Source = "config"
+	SourceFile    Source = "file"
+	SourceBuiltin Source = "built-in"
+)
+
+// Data holds the variables available to prompt templates.
+type Data struct {
+	Query      string
+	OS         string
+	Shell      string
+	Cwd        string
+	NumOptions int
+	Examples   []examples.Example
+
+	// Mode is the kind of request, e.g. agent or script, so the system
+	// template can adapt its rules.
+	Mode string
+
+	// Input is content piped into ted, used as context.
+	Input string
+
+	// Language is the language of the script generated by 'ted script'.
+	Language string
+
+	// Tools names the tools the model may call in agent mode, if any.
+	Tools []string
+
+	// ExitCode and Output describe the failed command passed to 'ted fix'.
+	ExitCode int
+	Output   string
+}
+
+// NewData returns template data for query, to be run in the named shell, with
+// the rest of the environment filled in.
+func NewData(query, shell string) Data {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+
+	return Data{
+		Query:      query,
+		OS:         runtime.GOOS,
+		Shell:      shell,
+		Cwd:        cwd,
+		NumOptions: 3,
+		Language:   "bash",
+	}
+}
+
+func GetPromptsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ted", "prompts"), nil
+}
+
+// GetTemplateFile returns the path of the override file for the named template.
+func GetTemplateFile(name string) (string, error) {
+	dir, err := GetPromptsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".tmpl"), nil
+}
+
+// Default returns the built-in text of the named template.
+func Default(name string) (string, error) {
+	if !slices.Contains(Names, name) {
+		return "", fmt.Errorf("unknown prompt template %q", name)
+	}
+
+	data, err := defaults.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Lookup returns the effective text of the named template. An entry in
+// overrides (the prompts section of config.yaml) takes precedence over a file
+// in ~/.ted/prompts, which takes precedence over the built-in default.
+func Lookup(name string, overrides map[string]string) (string, Source, error) {
+	if !slices.Contains(Names, name) {
+		return "", "", fmt.Errorf("unknown prompt template %q", name)
+	}
+
+	if text, ok := overrides[name]; ok && text != "" {
+		return text, SourceConfig, nil
+	}
+
+	path, err := GetTemplateFile(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), SourceFile, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	text, err := Default(name)
+	if err != nil {
+		return "", "", err
+	}
+	return text, SourceBuiltin, nil
+}
+
+// Render executes the effective named template with data.
+func Render(name string, overrides map[string]string, data Data) (string, error) {
+	text, source, err := Lookup(name, overrides)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s prompt template (%s): %w", name, source, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt template (%s): %w", name, source, err)
+	}
+
+	return buf.String(), nil
+}
+
+// Check parses text as a prompt template and executes it with sample data.
+func Check(text string) error {
+	tmpl, err := template.New("check").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+	data := NewData("list files", "sh")
+	data.Examples = []examples.Example{{Query: "list files", Command: "ls"}}
+	return tmpl.Execute(&bytes.Buffer{}, data)
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/cache"
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/logging"
+	"github.com/jadenpxrk/ted/internal/prompts"
+)
+
+// CachePolicy controls how a request uses the response cache. Skip bypasses
+// it entirely; Refresh ignores a cached answer but stores the new one.
+type CachePolicy struct {
+	Skip    bool
+	Refresh bool
+}
+
+// CacheRequest describes a request in mode for the cache. The system
+// instruction, the named prompt templates and any piped input are part of the
+// key, so editing a template or examples doesn't return stale answers.
+func CacheRequest(cfg *config.Config, mode string, data prompts.Data, templates ...string) (cache.Request, error) {
+	data.Mode = mode
+	system, err := SystemInstruction(cfg, data)
+	if err != nil {
+		return cache.Request{}, err
+	}
+
+	parts := []string{system, data.Input}
+	for _, name := range templates {
+		text, _, err := prompts.Lookup(name, cfg.Prompts)
+		if err != nil {
+			return cache.Request{}, err
+		}
+		parts = append(parts, text)
+	}
+
+	return cache.Request{
+		Mode:        mode,
+		Provider:    "gemini",
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		Query:       data.Query,
+		OS:          data.OS,
+		Shell:       data.Shell,
+		Context:     parts,
+	}, nil
+}
+
+// Cached returns the cached response for req if there is one, or calls
+// generate and caches its response. The returned entry is nil unless the
+// response came from the cache. Cache errors are ignored so they never stop
+// a request.
+func Cached[T any](cfg *config.Config, req cache.Request, policy CachePolicy, generate func() (*T, error)) (*T, *history.CacheEntry, error) {
+	enabled := !policy.Skip && cfg.CacheTTL > 0
+
+	if enabled && !policy.Refresh {
+		var cached T
+		if entry, err := cache.Get(req, &cached); err == nil && entry != nil {
+			logging.Logger().Info("cache hit", "mode", req.Mode, "model", req.Model, "age", time.Since(entry.Created).Round(time.Second))
+			return &cached, entry, nil
+		}
+	}
+
+	response, err := generate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if enabled {
+		_ = cache.Put(req, response, cfg.CacheTTL)
+	}
+	return response, nil, nil
+}
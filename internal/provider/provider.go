@@ -0,0 +1,83 @@
+// Package provider sets up model clients the way every ted front end needs
+// them: with the configured system instruction, timeouts and retries, usage
+// logging and the response cache.
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/examples"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/logging"
+	"github.com/jadenpxrk/ted/internal/prompts"
+)
+
+// ErrNoAPIKey is returned when no Gemini API key is configured.
+var ErrNoAPIKey = fmt.Errorf("gemini API key not configured. Run 'ted settings' to set it up")
+
+// NewClient creates a Gemini client for cfg with the system instruction and
+// few-shot examples rendered for data. The tokens of every request are logged
+// under mode, and so are its records in the debug log.
+func NewClient(cfg *config.Config, mode string, data prompts.Data) (*gemini.Client, error) {
+	if cfg.GeminiAPIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+
+	data.Mode = mode
+	system, err := SystemInstruction(cfg, data)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := gemini.NewClient(cfg.GeminiAPIKey, cfg.Model, cfg.Temperature)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Gemini client: %w", err)
+	}
+	logger := logging.Logger().With("mode", mode)
+	logger.Debug("system instruction", "text", system)
+
+	client.SetLogger(logger)
+	client.SetSystemInstruction(system)
+	client.SetTimeout(cfg.RequestTimeout)
+	client.SetMaxRetries(cfg.MaxRetries)
+	client.SetUsageHandler(func(u gemini.Usage) {
+		RecordUsage(cfg.Model, mode, u)
+	})
+
+	return client, nil
+}
+
+// SystemInstruction renders the system template with the stored few-shot
+// examples.
+func SystemInstruction(cfg *config.Config, data prompts.Data) (string, error) {
+	stored, err := examples.Load()
+	if err != nil {
+		return "", err
+	}
+	data.Examples = stored
+
+	return prompts.Render(prompts.System, cfg.Prompts, data)
+}
+
+// RecordUsage logs the tokens used by a request in mode. Failures are ignored
+// so a busy or broken history database never stops a request.
+func RecordUsage(model, mode string, u gemini.Usage) {
+	hist, err := history.Load()
+	if err != nil {
+		return
+	}
+	defer hist.Close()
+
+	_ = hist.AddUsage(history.UsageRecord{
+		Time: time.Now(),
+		Mode: mode,
+		Tokens: history.Tokens{
+			Model:          model,
+			PromptTokens:   u.PromptTokens,
+			ResponseTokens: u.ResponseTokens,
+		},
+	})
+}
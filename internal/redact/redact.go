@@ -0,0 +1,51 @@
+package redact
+
+import "regexp"
+
+// Placeholder replaces every secret found in text.
+const Placeholder = "[REDACTED]"
+
+// patterns match common secrets. Where a pattern has a capture group, only
+// the group is replaced so the surrounding key name stays readable.
+var patterns = []*regexp.Regexp{
+	// Private key blocks
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// AWS access key IDs
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// Google API keys
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+	// GitHub tokens
+	regexp.MustCompile(`\b(?:gh[pousr]_[0-9A-Za-z]{36,}|github_pat_[0-9A-Za-z_]{22,})\b`),
+	// Slack tokens
+	regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z-]{10,}\b`),
+	// OpenAI and Anthropic style keys
+	regexp.MustCompile(`\bsk-[0-9A-Za-z_\-]{20,}\b`),
+	// JSON web tokens
+	regexp.MustCompile(`\beyJ[0-9A-Za-z_\-]+\.eyJ[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]+\b`),
+	// Bearer and basic authorization headers
+	regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+([0-9A-Za-z._~+/\-]{16,}=*)`),
+	// Passwords in URLs
+	regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.\-]*://[^:/\s@]+:([^@/\s]+)@`),
+	// API keys passed as a URL query parameter
+	regexp.MustCompile(`[?&]key=([^&\s"']+)`),
+	// key=value and key: value assignments with secret-looking names
+	regexp.MustCompile(`(?i)\b[a-z0-9_.\-]*(?:password|passwd|secret|token|api[_\-]?key|access[_\-]?key|private[_\-]?key|credentials?)[a-z0-9_.\-]*["']?\s*[:=]\s*["']?([^\s"',;]+)`),
+}
+
+// String returns text with secrets replaced by Placeholder.
+func String(text string) string {
+	for _, pattern := range patterns {
+		if pattern.NumSubexp() == 0 {
+			text = pattern.ReplaceAllString(text, Placeholder)
+			continue
+		}
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			loc := pattern.FindStringSubmatchIndex(match)
+			if loc == nil || loc[2] < 0 {
+				return Placeholder
+			}
+			return match[:loc[2]] + Placeholder + match[loc[3]:]
+		})
+	}
+	return text
+}
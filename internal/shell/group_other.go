@@ -0,0 +1,28 @@
+//go:build !unix
+
+package shell
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// Without process groups, signals only reach the shell.
+func startInGroup(cmd *exec.Cmd, stdin io.Reader) (restore func(), err error) {
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return func() {}, nil
+}
+
+func signalGroup(process *os.Process, sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		return process.Kill()
+	}
+	return process.Signal(sig)
+}
+
+func waitGroup(process *os.Process, deadline time.Time) {}
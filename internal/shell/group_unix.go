@@ -0,0 +1,69 @@
+//go:build unix
+
+package shell
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// startInGroup starts cmd in a process group of its own, so that the timeout
+// and forwarded signals reach every process the command starts and not just
+// the shell. When stdin is the terminal ted is in the foreground of, the
+// group is made the terminal's foreground group, so Ctrl+C and Ctrl+\ go to
+// the command. The returned function gives the terminal back to ted once the
+// command has exited.
+func startInGroup(cmd *exec.Cmd, stdin io.Reader) (restore func(), err error) {
+	restore = func() {}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if tty, ok := stdin.(*os.File); ok {
+		fd := int(tty.Fd())
+		if foreground, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP); err == nil && foreground == syscall.Getpgrp() {
+			cmd.SysProcAttr.Foreground = true
+			cmd.SysProcAttr.Ctty = fd
+			restore = func() {
+				// ted is in the background now, and taking the terminal back
+				// from there raises SIGTTOU, which would stop it unless ignored.
+				signal.Ignore(syscall.SIGTTOU)
+				defer signal.Reset(syscall.SIGTTOU)
+				_ = unix.IoctlSetPointerInt(fd, unix.TIOCSPGRP, syscall.Getpgrp())
+			}
+
+			// Ctrl+Z would stop the command with the terminal still its own,
+			// and ted can't hand a stopped job to the user's shell, so the
+			// command starts with SIGTSTP ignored.
+			signal.Ignore(syscall.SIGTSTP)
+			defer signal.Reset(syscall.SIGTSTP)
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		restore()
+		return nil, err
+	}
+	return restore, nil
+}
+
+// signalGroup sends sig to every process in the group led by process.
+func signalGroup(process *os.Process, sig syscall.Signal) error {
+	return syscall.Kill(-process.Pid, sig)
+}
+
+// waitGroup waits for the processes left in the group led by process after
+// the shell has exited, and kills any still running at deadline.
+func waitGroup(process *os.Process, deadline time.Time) {
+	for syscall.Kill(-process.Pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = signalGroup(process, syscall.SIGKILL)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
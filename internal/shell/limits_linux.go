@@ -0,0 +1,37 @@
+//go:build linux
+
+package shell
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LimitsSupported reports whether Run can apply resource limits.
+const LimitsSupported = true
+
+// limitCommand makes cmd set limits before it starts the shell, so that they
+// apply from its first instruction and every process it starts inherits them.
+// The limits are set with ulimit in sh, which then execs the shell.
+func limitCommand(cmd *exec.Cmd, limits Limits) {
+	var script []string
+	if limits.CPU > 0 {
+		// ulimit -t counts whole seconds; round up so a limit is never zero.
+		seconds := int64((limits.CPU + time.Second - 1) / time.Second)
+		script = append(script, "ulimit -t "+strconv.FormatInt(seconds, 10))
+	}
+	if limits.Memory > 0 {
+		// KiB, rounded up.
+		script = append(script, "ulimit -v "+strconv.FormatInt((limits.Memory+1023)/1024, 10))
+	}
+	if limits.FileSize > 0 {
+		// 512-byte blocks, rounded up.
+		script = append(script, "ulimit -f "+strconv.FormatInt((limits.FileSize+511)/512, 10))
+	}
+	script = append(script, `exec "$0" "$@"`)
+
+	cmd.Args = append([]string{"/bin/sh", "-c", strings.Join(script, " && "), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = "/bin/sh"
+}
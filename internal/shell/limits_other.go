@@ -0,0 +1,10 @@
+//go:build !linux
+
+package shell
+
+import "os/exec"
+
+// LimitsSupported reports whether Run can apply resource limits.
+const LimitsSupported = false
+
+func limitCommand(cmd *exec.Cmd, limits Limits) {}
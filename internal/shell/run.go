@@ -0,0 +1,194 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stopGrace is how long a command has to exit after SIGTERM when it times
+// out before it is killed.
+const stopGrace = 5 * time.Second
+
+// ErrLimitsUnsupported is returned by Run when limits are set on a platform
+// other than Linux.
+var ErrLimitsUnsupported = errors.New("resource limits are only supported on Linux")
+
+// RunOptions controls how Run executes a command. The zero value runs it
+// with no timeout, output capture or resource limits.
+type RunOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Timeout sends the command SIGTERM after this long, and kills it
+	// stopGrace later if it is still running. Zero means no timeout.
+	Timeout time.Duration
+
+	// CaptureLimit keeps the last CaptureLimit bytes of the command's
+	// combined stdout and stderr while still passing them through. Capturing
+	// makes the output a pipe rather than the terminal, which full-screen
+	// programs don't work with. Zero disables capture.
+	CaptureLimit int
+
+	// Limits are set before the shell starts, and so apply to every process
+	// it starts.
+	Limits Limits
+}
+
+// Limits are resource limits for a command. Zero values are unlimited.
+type Limits struct {
+	// CPU is the CPU time the command may use.
+	CPU time.Duration
+	// Memory caps the address space of each process, in bytes.
+	Memory int64
+	// FileSize caps the size of each file the command writes, in bytes.
+	FileSize int64
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// Result describes how a command run by Run ended.
+type Result struct {
+	Started  time.Time
+	Finished time.Time
+
+	// Output is the captured end of the command's output, and Truncated
+	// reports whether earlier output was dropped to stay within the limit.
+	Output    string
+	Truncated bool
+
+	// TimedOut is set if the command was stopped by the timeout.
+	TimedOut bool
+}
+
+// Run runs command in the shell and waits for it. The error is the one
+// returned by exec.Cmd.Wait, so a non-zero exit is an *exec.ExitError.
+//
+// The command runs in a process group of its own, and the timeout and
+// signals are sent to the whole group. While it runs, Ctrl+C and Ctrl+\ go
+// to the command instead of stopping ted, and SIGTERM and SIGHUP sent to ted
+// are forwarded to it.
+func (e *Executor) Run(command string, opts RunOptions) (*Result, error) {
+	if !opts.Limits.IsZero() && !LimitsSupported {
+		return nil, ErrLimitsUnsupported
+	}
+
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := e.CommandContext(ctx, command)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if !opts.Limits.IsZero() {
+		limitCommand(cmd, opts.Limits)
+	}
+	var killAt time.Time
+	cmd.Cancel = func() error {
+		killAt = time.Now().Add(stopGrace)
+		return signalGroup(cmd.Process, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = stopGrace
+
+	var capture *tailBuffer
+	if opts.CaptureLimit > 0 {
+		capture = &tailBuffer{limit: opts.CaptureLimit}
+		cmd.Stdout = io.MultiWriter(writerOrDiscard(opts.Stdout), capture)
+		cmd.Stderr = io.MultiWriter(writerOrDiscard(opts.Stderr), capture)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+
+	result := &Result{Started: time.Now()}
+	restoreTerminal, err := startInGroup(cmd, opts.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for sig := range signals {
+			_ = signalGroup(cmd.Process, sig.(syscall.Signal))
+		}
+	}()
+
+	err = cmd.Wait()
+	restoreTerminal()
+	result.Finished = time.Now()
+	result.TimedOut = ctx.Err() == context.DeadlineExceeded
+	if result.TimedOut {
+		// The shell has exited, but processes it started may still be
+		// shutting down.
+		waitGroup(cmd.Process, killAt)
+	}
+	if capture != nil {
+		result.Output, result.Truncated = capture.String()
+	}
+	if result.TimedOut {
+		err = fmt.Errorf("timed out after %s: %w", opts.Timeout, err)
+	}
+	return result, err
+}
+
+func writerOrDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
+
+// tailBuffer keeps the last limit bytes written to it. Stdout and stderr
+// are copied by separate goroutines, so writes are locked.
+type tailBuffer struct {
+	mu      sync.Mutex
+	limit   int
+	buf     []byte
+	dropped bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	// Trim in batches so a chatty command doesn't copy on every write.
+	if len(b.buf) > 2*b.limit {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.limit:]...)
+		b.dropped = true
+	}
+	return len(p), nil
+}
+
+// String returns the captured output and whether any was dropped. A
+// multi-byte character cut at the start is removed.
+func (b *tailBuffer) String() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.buf
+	dropped := b.dropped
+	if len(out) > b.limit {
+		out = out[len(out)-b.limit:]
+		dropped = true
+	}
+	return strings.ToValidUTF8(string(out), ""), dropped
+}
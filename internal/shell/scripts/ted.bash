@@ -0,0 +1,72 @@
+# ted shell integration for bash.
+# Add this to ~/.bashrc:
+#   eval "$(ted shell-init bash)"
+#
+# Type a request on the command line and press Ctrl+G to replace it with
+# the suggested command. To use another key, rebind the widget:
+#   bind -x '"\C-x\C-t": _ted_widget'
+#
+# The last command and its exit status are kept in shell variables and only
+# passed to 'ted fix', so command lines never reach other programs'
+# environments.
+
+_ted_widget() {
+  local query="$READLINE_LINE"
+  [[ -z "$query" ]] && return
+
+  local result
+  if result=$(command ted agent --print -- "$query" 2>/dev/null) && [[ -n "$result" ]]; then
+    READLINE_LINE="$result"
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+}
+
+bind -x '"\C-g": _ted_widget'
+
+# A command is recorded when it adds a history entry, so running the same
+# command twice records the second status. A command that ran without adding
+# one, because HISTCONTROL or HISTIGNORE kept it out, has unknown text, so
+# the last command is forgotten rather than paired with its status. Bash 4.4
+# and later tell that case from an empty command line with the \# command
+# number; older versions keep the last command in both cases. The first
+# prompt only notes where history starts.
+#
+# The hook runs first in PROMPT_COMMAND, so it returns the status it found
+# for the prompt commands after it.
+_ted_precmd() {
+  local exit_status=$?
+  local started=${_ted_history_number+1} ran=0
+  if (( BASH_VERSINFO[0] * 100 + BASH_VERSINFO[1] >= 404 )); then
+    local number='\#'
+    number=${number@P}
+    [[ "$number" != "$_ted_command_number" ]] && ran=1
+    _ted_command_number=$number
+  fi
+
+  local entry pattern='^ *([0-9]+)\*? +(.*)$'
+  entry=$(HISTTIMEFORMAT= builtin history 1)
+  if [[ $entry =~ $pattern ]] && [[ "${BASH_REMATCH[1]}" != "$_ted_history_number" ]]; then
+    _ted_history_number=${BASH_REMATCH[1]}
+    if [[ -n $started && "${BASH_REMATCH[2]}" != "ted fix"* ]]; then
+      _ted_last_command="${BASH_REMATCH[2]}"
+      _ted_last_status=$exit_status
+    fi
+  elif [[ -n $started && $ran == 1 ]]; then
+    _ted_last_command=
+    _ted_last_status=
+  fi
+  _ted_history_number=${_ted_history_number-}
+  return $exit_status
+}
+
+ted() {
+  if [[ "$1" == fix ]]; then
+    TED_LAST_COMMAND="$_ted_last_command" TED_LAST_STATUS="$_ted_last_status" command ted "$@"
+  else
+    command ted "$@"
+  fi
+}
+
+if [[ ";$PROMPT_COMMAND;" != *";_ted_precmd;"* ]]; then
+  PROMPT_COMMAND="_ted_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
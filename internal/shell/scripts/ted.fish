@@ -0,0 +1,40 @@
+# ted shell integration for fish.
+# Add this to ~/.config/fish/config.fish:
+#   ted shell-init fish | source
+#
+# Type a request on the command line and press Ctrl+G to replace it with
+# the suggested command. To use another key, rebind the widget:
+#   bind \cx\ct _ted_widget
+#
+# The last command and its exit status are kept in shell variables and only
+# passed to 'ted fix', so command lines never reach other programs'
+# environments.
+
+function _ted_widget
+    set -l query (commandline)
+    test -z "$query"; and return
+
+    set -l result (command ted agent --print -- "$query" 2>/dev/null | string collect)
+    if test $status -eq 0 -a -n "$result"
+        commandline -r -- $result
+        commandline -f end-of-line
+    end
+    commandline -f repaint
+end
+
+bind \cg _ted_widget
+
+function _ted_postexec --on-event fish_postexec
+    set -l exit_status $status
+    string match -q 'ted fix*' -- $argv[1]; and return
+    set -g _ted_last_command $argv[1]
+    set -g _ted_last_status $exit_status
+end
+
+function ted
+    if test "$argv[1]" = fix
+        TED_LAST_COMMAND=$_ted_last_command TED_LAST_STATUS=$_ted_last_status command ted $argv
+    else
+        command ted $argv
+    end
+end
@@ -0,0 +1,114 @@
+package shell
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//go:embed scripts/*
+var scripts embed.FS
+
+// Names lists the shells that have an integration script.
+var Names = []string{"zsh", "bash", "fish"}
+
+// InitScript returns the integration script for the named shell.
+func InitScript(name string) (string, error) {
+	if !slices.Contains(Names, name) {
+		return "", fmt.Errorf("unsupported shell %q", name)
+	}
+
+	data, err := scripts.ReadFile("scripts/ted." + name)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Executor runs commands in the user's shell.
+type Executor struct {
+	// Name is the shell's base name, e.g. zsh.
+	Name string
+	// Path is the resolved path of the shell binary.
+	Path string
+	// RCFile is sourced before each command so aliases and functions are
+	// available. It is empty when no rc file is configured.
+	RCFile string
+}
+
+// Resolve picks the shell used to run commands: configured if set, otherwise
+// $SHELL, otherwise sh. If the chosen shell can't be found it falls back to sh
+// and returns the executor together with an error describing the fallback.
+func Resolve(configured, rcFile string) (*Executor, error) {
+	candidate := configured
+	if candidate == "" {
+		candidate = os.Getenv("SHELL")
+	}
+	if candidate == "" {
+		candidate = "sh"
+	}
+
+	rcFile = expandHome(rcFile)
+
+	path, err := exec.LookPath(candidate)
+	if err == nil {
+		return &Executor{Name: filepath.Base(path), Path: path, RCFile: rcFile}, nil
+	}
+
+	fallback, fallbackErr := exec.LookPath("sh")
+	if fallbackErr != nil {
+		fallback = "/bin/sh"
+	}
+	// The rc file was written for the missing shell, so don't source it in sh.
+	return &Executor{Name: "sh", Path: fallback}, fmt.Errorf("shell %q not found, using sh", candidate)
+}
+
+// Command returns an exec.Cmd that runs command in the shell, sourcing the rc
+// file first when one is configured.
+func (e *Executor) Command(command string) *exec.Cmd {
+	return e.CommandContext(context.Background(), command)
+}
+
+// CommandContext is like Command but kills the shell when ctx is done.
+func (e *Executor) CommandContext(ctx context.Context, command string) *exec.Cmd {
+	script := command
+	if e.RCFile != "" {
+		script = e.sourceLine() + "\n" + command
+	}
+	return exec.CommandContext(ctx, e.Path, "-c", script)
+}
+
+func (e *Executor) sourceLine() string {
+	rc := quote(e.RCFile)
+	switch e.Name {
+	case "bash":
+		// Aliases are only expanded in non-interactive bash when enabled, and
+		// only on lines read after they are defined.
+		return "shopt -s expand_aliases\nsource " + rc + " >/dev/null 2>&1"
+	case "zsh", "fish":
+		return "source " + rc + " >/dev/null 2>&1"
+	default:
+		return ". " + rc + " >/dev/null 2>&1"
+	}
+}
+
+// quote wraps s in single quotes for use in a shell script.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
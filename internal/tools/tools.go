@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/redact"
+)
+
+// Tool names.
+const (
+	ListDir   = "list_dir"
+	ReadFile  = "read_file"
+	Which     = "which"
+	Help      = "help"
+	GitStatus = "git_status"
+)
+
+// Names lists every tool, in the order they are offered to the model.
+var Names = []string{ListDir, ReadFile, Which, Help, GitStatus}
+
+// DefaultNames are the tools enabled when none are configured. They only
+// look at installed programs and their man pages. The others read the
+// working directory, and git_status runs git, which can run commands
+// configured in the repository such as core.fsmonitor, so they are opt-in.
+var DefaultNames = []string{Which, Help}
+
+const (
+	// DefaultOutputLimit is the number of bytes of tool output sent to the
+	// model by default.
+	DefaultOutputLimit = 4096
+
+	runTimeout      = 5 * time.Second
+	maxDirEntries   = 200
+	defaultReadLine = 40
+	maxReadLines    = 200
+)
+
+var commandName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+\-]*$`)
+
+var specs = map[string]gemini.Tool{
+	ListDir: {
+		Name:        ListDir,
+		Description: "List the entries of a directory in the working directory, marking subdirectories with a trailing slash and showing file sizes",
+		Params: []gemini.ToolParam{
+			{Name: "path", Type: "string", Description: "Directory to list, relative to the working directory. Defaults to ."},
+		},
+	},
+	ReadFile: {
+		Name:        ReadFile,
+		Description: "Read the first lines of a text file in the working directory. Hidden files can't be read",
+		Params: []gemini.ToolParam{
+			{Name: "path", Type: "string", Description: "File to read, relative to the working directory", Required: true},
+			{Name: "lines", Type: "integer", Description: fmt.Sprintf("Number of lines to read, up to %d. Defaults to %d", maxReadLines, defaultReadLine)},
+		},
+	},
+	Which: {
+		Name:        Which,
+		Description: "Find the path of a program on PATH, to check whether it is installed",
+		Params: []gemini.ToolParam{
+			{Name: "program", Type: "string", Description: "Program name, e.g. rg", Required: true},
+		},
+	},
+	Help: {
+		Name:        Help,
+		Description: "Show the man page of a program, to check which flags it supports",
+		Params: []gemini.ToolParam{
+			{Name: "program", Type: "string", Description: "Program name, e.g. tar", Required: true},
+		},
+	},
+	GitStatus: {
+		Name:        GitStatus,
+		Description: "Show the current branch and changed files of a git repository",
+		Params: []gemini.ToolParam{
+			{Name: "path", Type: "string", Description: "Directory inside the repository. Defaults to ."},
+		},
+	},
+}
+
+// Specs returns the descriptions of the named tools for the model. Unknown
+// names are ignored.
+func Specs(names []string) []gemini.Tool {
+	var tools []gemini.Tool
+	for _, name := range Names {
+		if slices.Contains(names, name) {
+			tools = append(tools, specs[name])
+		}
+	}
+	return tools
+}
+
+// Run executes call and returns its output with secrets redacted, cut to
+// limit bytes. Only tools in enabled may run. Failures are reported in the
+// output so the model can react to them.
+func Run(ctx context.Context, call gemini.ToolCall, enabled []string, limit int) string {
+	if !slices.Contains(enabled, call.Name) {
+		return fmt.Sprintf("error: unknown tool %q", call.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	var output string
+	var err error
+	switch call.Name {
+	case ListDir:
+		output, err = listDir(stringArg(call.Args, "path", "."))
+	case ReadFile:
+		output, err = readFile(stringArg(call.Args, "path", ""), intArg(call.Args, "lines", defaultReadLine))
+	case Which:
+		output, err = which(stringArg(call.Args, "program", ""))
+	case Help:
+		output, err = help(ctx, stringArg(call.Args, "program", ""))
+	case GitStatus:
+		output, err = gitStatus(ctx, stringArg(call.Args, "path", "."))
+	}
+	if err != nil {
+		output = "error: " + err.Error()
+	}
+
+	return truncate(redact.String(output), limit)
+}
+
+// Describe formats call for display, e.g. read_file(path=go.mod).
+func Describe(call gemini.ToolCall) string {
+	var args []string
+	for _, param := range specs[call.Name].Params {
+		if value, ok := call.Args[param.Name]; ok {
+			args = append(args, fmt.Sprintf("%s=%v", param.Name, value))
+		}
+	}
+	return fmt.Sprintf("%s(%s)", call.Name, strings.Join(args, ", "))
+}
+
+func listDir(path string) (string, error) {
+	path, err := resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "(empty directory)", nil
+	}
+
+	var b strings.Builder
+	for i, entry := range entries {
+		if i == maxDirEntries {
+			fmt.Fprintf(&b, "... and %d more entries\n", len(entries)-maxDirEntries)
+			break
+		}
+		if entry.IsDir() {
+			fmt.Fprintf(&b, "%s/\n", entry.Name())
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(&b, "%s\n", entry.Name())
+			continue
+		}
+		fmt.Fprintf(&b, "%s\t%d bytes\n", entry.Name(), info.Size())
+	}
+	return b.String(), nil
+}
+
+func readFile(path string, lines int) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	lines = max(1, min(lines, maxReadLines))
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Opening a FIFO would block until something writes to it, so open
+	// without blocking and then check what was opened.
+	file, err := os.OpenFile(resolved, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+
+	var b strings.Builder
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < lines && scanner.Scan(); i++ {
+		line := scanner.Text()
+		if strings.ContainsRune(line, 0) {
+			return "", fmt.Errorf("%s is a binary file", path)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func which(program string) (string, error) {
+	if !commandName.MatchString(program) {
+		return "", fmt.Errorf("invalid program name %q", program)
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return fmt.Sprintf("%s is not installed", program), nil
+	}
+	return path, nil
+}
+
+func help(ctx context.Context, program string) (string, error) {
+	if !commandName.MatchString(program) {
+		return "", fmt.Errorf("invalid program name %q", program)
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Sprintf("%s is not installed", program), nil
+	}
+
+	// Only man is run: running the program itself with --help isn't
+	// read-only, since nothing guarantees it honors the flag.
+	if _, err := exec.LookPath("man"); err != nil {
+		return "", fmt.Errorf("man is not installed")
+	}
+	cmd := exec.CommandContext(ctx, "man", program)
+	cmd.Env = append(os.Environ(), "MANPAGER=cat", "PAGER=cat", "MANWIDTH=80")
+	output, err := cmd.Output()
+	if err != nil || len(output) == 0 {
+		return "", fmt.Errorf("no man page for %s", program)
+	}
+	return stripOverstrike(string(output)), nil
+}
+
+func gitStatus(ctx context.Context, path string) (string, error) {
+	path, err := resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := exec.CommandContext(ctx, "git", "-C", path, "status", "--short", "--branch").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// resolvePath resolves path, following symlinks, and checks that it is the
+// working directory or inside it and isn't hidden or inside a hidden
+// directory such as .git or .ssh. This keeps the model, and any instructions
+// injected into what it reads, away from files it wasn't pointed at.
+func resolvePath(path string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return "", err
+	}
+
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(cwd, target)
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("%s is outside the working directory", path)
+	}
+	if rel == "." {
+		return resolved, nil
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return "", fmt.Errorf("%s is hidden", path)
+		}
+	}
+	return resolved, nil
+}
+
+// stripOverstrike removes the backspace sequences man uses for bold and
+// underlined text.
+func stripOverstrike(text string) string {
+	out := make([]rune, 0, len(text))
+	for _, r := range text {
+		if r == '\b' {
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+func truncate(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "\n[output truncated]"
+}
+
+func stringArg(args map[string]any, name, fallback string) string {
+	if value, ok := args[name].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func intArg(args map[string]any, name string, fallback int) int {
+	if value, ok := args[name].(float64); ok {
+		return int(value)
+	}
+	return fallback
+}
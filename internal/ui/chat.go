@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Chat entry roles.
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+)
+
+// ChatEntry is one message shown in the chat transcript. Command is set on
+// model replies that suggested a command.
+type ChatEntry struct {
+	Role    string
+	Text    string
+	Command string
+}
+
+// ChatHandlers connect the chat UI to the model and the shell. Both are
+// called outside the UI loop.
+type ChatHandlers struct {
+	// Send sends a user message and returns the model's reply.
+	Send func(text string) (ChatEntry, error)
+	// Run executes a command and returns its combined output, with secrets
+	// redacted, and exit status. The output is sent to the model.
+	Run func(command string) (string, int)
+}
+
+type chatReplyMsg struct {
+	entry ChatEntry
+	err   error
+}
+
+type commandDoneMsg struct {
+	command  string
+	output   string
+	exitCode int
+}
+
+// ChatModel is a REPL for multi-turn conversations. Finished messages are
+// printed above the input line so they stay in the terminal scrollback.
+type ChatModel struct {
+	handlers ChatHandlers
+	previous []ChatEntry
+	commands []string
+	input    []rune
+	status   string
+	busy     bool
+}
+
+func NewChatModel(handlers ChatHandlers, previous []ChatEntry) ChatModel {
+	m := ChatModel{
+		handlers: handlers,
+		previous: previous,
+	}
+	for _, entry := range previous {
+		if entry.Role == RoleModel && entry.Command != "" {
+			m.commands = append(m.commands, entry.Command)
+		}
+	}
+	return m
+}
+
+// Init replays the entries of a resumed session.
+func (m ChatModel) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	number := 0
+	for _, entry := range m.previous {
+		if entry.Role == RoleModel && entry.Command != "" {
+			number++
+			cmds = append(cmds, tea.Println(m.renderEntry(entry, number)))
+			continue
+		}
+		cmds = append(cmds, tea.Println(m.renderEntry(entry, 0)))
+	}
+	return tea.Sequence(cmds...)
+}
+
+func (m ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyCtrlD, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if m.busy {
+				return m, nil
+			}
+			return m.submit()
+		case tea.KeyBackspace:
+			if len(m.input) > 0 {
+				m.input = m.input[:len(m.input)-1]
+			}
+		case tea.KeyCtrlU:
+			m.input = nil
+		case tea.KeySpace:
+			m.input = append(m.input, ' ')
+		case tea.KeyRunes:
+			m.input = append(m.input, msg.Runes...)
+		}
+
+	case chatReplyMsg:
+		m.busy = false
+		m.status = ""
+		if msg.err != nil {
+			return m, tea.Println(colors.ErrorStyle.Render(fmt.Sprintf("Error: %v", msg.err)))
+		}
+		number := 0
+		if msg.entry.Command != "" {
+			m.commands = append(m.commands, msg.entry.Command)
+			number = len(m.commands)
+		}
+		return m, tea.Println(m.renderEntry(msg.entry, number))
+
+	case commandDoneMsg:
+		output := strings.TrimRight(msg.output, "\n")
+		if output == "" {
+			output = "(no output)"
+		}
+		printed := fmt.Sprintf("%s\n%s",
+			colors.DetailBoxStyle.Render(output),
+			colors.TimeStyle.Render(fmt.Sprintf("exit status %d", msg.exitCode)))
+
+		feedback := fmt.Sprintf("I ran `%s` and it exited with status %d. Output:\n%s", msg.command, msg.exitCode, output)
+		m.status = "Thinking..."
+		return m, tea.Sequence(tea.Println(printed), m.send(feedback))
+	}
+
+	return m, nil
+}
+
+// submit handles the current input line: a /command or a message to send.
+func (m ChatModel) submit() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(string(m.input))
+	m.input = nil
+	if text == "" {
+		return m, nil
+	}
+
+	fields := strings.Fields(text)
+	switch fields[0] {
+	case "/quit", "/exit":
+		return m, tea.Quit
+
+	case "/run":
+		if len(m.commands) == 0 {
+			return m, tea.Println(colors.ErrorStyle.Render("No command has been suggested yet."))
+		}
+		number := len(m.commands)
+		if len(fields) > 1 {
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 || n > len(m.commands) {
+				return m, tea.Println(colors.ErrorStyle.Render(fmt.Sprintf("Enter a command number between 1 and %d.", len(m.commands))))
+			}
+			number = n
+		}
+		command := m.commands[number-1]
+		m.busy = true
+		m.status = fmt.Sprintf("Running `%s`...", command)
+		return m, tea.Sequence(
+			tea.Println(colors.RunningStyle.Render(fmt.Sprintf("Running `%s`", command))),
+			m.run(command),
+		)
+	}
+
+	m.busy = true
+	m.status = "Thinking..."
+	return m, tea.Sequence(
+		tea.Println(m.renderEntry(ChatEntry{Role: RoleUser, Text: text}, 0)),
+		m.send(text),
+	)
+}
+
+func (m ChatModel) send(text string) tea.Cmd {
+	send := m.handlers.Send
+	return func() tea.Msg {
+		entry, err := send(text)
+		return chatReplyMsg{entry: entry, err: err}
+	}
+}
+
+func (m ChatModel) run(command string) tea.Cmd {
+	run := m.handlers.Run
+	return func() tea.Msg {
+		output, exitCode := run(command)
+		return commandDoneMsg{command: command, output: output, exitCode: exitCode}
+	}
+}
+
+func (m ChatModel) renderEntry(entry ChatEntry, number int) string {
+	if entry.Role == RoleUser {
+		return colors.PromptStyle.Render("> ") + colors.QueryStyle.Render(entry.Text)
+	}
+
+	content := colors.EntryStyle.Render(entry.Text)
+	if entry.Command != "" {
+		content += fmt.Sprintf("\n%s %s",
+			colors.TimeStyle.Render(fmt.Sprintf("[%d]", number)),
+			colors.CommandStyle.Render(entry.Command))
+	}
+	return content + "\n"
+}
+
+func (m ChatModel) View() string {
+	var b strings.Builder
+	if m.status != "" {
+		b.WriteString(colors.ThinkingStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(colors.PromptStyle.Render("> "))
+	b.WriteString(string(m.input))
+	if !m.busy {
+		b.WriteString("█")
+	}
+	b.WriteString("\n")
+	b.WriteString(colors.TimeStyle.Render("Enter send • /run [n] run a suggested command • /quit exit"))
+	return b.String()
+}
@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/colors"
+	"github.com/jadenpxrk/ted/internal/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PlanStep is a step of a plan along with how it ended. Status is empty
+// until the step has been run or skipped, then one of the history.Step*
+// statuses. Started and Finished are set for steps that ran. Err is the
+// error a failed step ended with; ExitCode is -1 if it couldn't be started
+// or was killed by a signal.
+type PlanStep struct {
+	Command     string
+	Explanation string
+	Safe        bool
+	Edited      bool
+	Status      string
+	ExitCode    int
+	Err         error
+	Started     time.Time
+	Finished    time.Time
+}
+
+// StepRunner runs the command of a step, reading from stdin and writing to
+// stdout and stderr, which are the terminal.
+type StepRunner func(command string, stdin io.Reader, stdout, stderr io.Writer) error
+
+type stepDoneMsg struct {
+	err error
+}
+
+// execStep hands the terminal to a StepRunner through tea.Exec.
+type execStep struct {
+	run     StepRunner
+	command string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func (e *execStep) Run() error {
+	return e.run(e.command, e.stdin, e.stdout, e.stderr)
+}
+
+func (e *execStep) SetStdin(r io.Reader)  { e.stdin = r }
+func (e *execStep) SetStdout(w io.Writer) { e.stdout = w }
+func (e *execStep) SetStderr(w io.Writer) { e.stderr = w }
+
+// PlanModel walks through a plan one step at a time, letting the user run,
+// edit or skip each step, or run the rest of the plan. Steps run in the
+// terminal with their output shown as usual, and the plan stops at the first
+// step that fails.
+type PlanModel struct {
+	steps    []PlanStep
+	runStep  StepRunner
+	stepDone func(index int, step PlanStep)
+	current  int
+	runAll   bool
+	editing  bool
+	input    []rune
+	running  bool
+	done     bool
+}
+
+// NewPlanModel returns a model for steps. runStep runs each step's command,
+// and stepDone is called with each step that ran as soon as it has finished.
+func NewPlanModel(steps []PlanStep, runStep StepRunner, stepDone func(index int, step PlanStep)) PlanModel {
+	return PlanModel{
+		steps:    steps,
+		runStep:  runStep,
+		stepDone: stepDone,
+	}
+}
+
+func (m PlanModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PlanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.running || m.done {
+			return m, nil
+		}
+		if m.editing {
+			return m.updateEdit(msg)
+		}
+
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return m.run()
+		case "a", "A":
+			m.runAll = true
+			return m.run()
+		case "e", "E":
+			m.editing = true
+			m.input = []rune(m.steps[m.current].Command)
+		case "s", "S":
+			m.steps[m.current].Status = history.StepSkipped
+			line := colors.TimeStyle.Render(fmt.Sprintf("Skipped step %d", m.current+1))
+			next, cmd := m.advance()
+			return next, tea.Sequence(tea.Println(line), cmd)
+		case "q", "Q", "n", "N", "ctrl+c", "esc":
+			return m.stop(colors.TimeStyle.Render("Plan cancelled."))
+		}
+
+	case stepDoneMsg:
+		m.running = false
+		step := &m.steps[m.current]
+		step.Finished = time.Now()
+
+		var exitErr *exec.ExitError
+		switch {
+		case msg.err == nil:
+			step.Status = history.StepSucceeded
+		case errors.As(msg.err, &exitErr):
+			step.Status = history.StepFailed
+			step.ExitCode = exitErr.ExitCode()
+			step.Err = msg.err
+		default:
+			step.Status = history.StepFailed
+			step.ExitCode = -1
+			step.Err = msg.err
+		}
+		m.stepDone(m.current, *step)
+
+		if step.Status == history.StepFailed {
+			line := colors.ErrorStyle.Render(fmt.Sprintf("✗ Step %d failed (%v). Stopping the plan.", m.current+1, msg.err))
+			return m.stop(line)
+		}
+
+		line := colors.SuccessStyle.Render(fmt.Sprintf("✓ Step %d succeeded", m.current+1))
+		next, cmd := m.advance()
+		return next, tea.Sequence(tea.Println(line), cmd)
+	}
+
+	return m, nil
+}
+
+// updateEdit handles keys while the current step's command is being edited.
+func (m PlanModel) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m.stop(colors.TimeStyle.Render("Plan cancelled."))
+	case tea.KeyEsc:
+		m.editing = false
+		m.input = nil
+	case tea.KeyEnter:
+		command := strings.TrimSpace(string(m.input))
+		if command != "" && command != m.steps[m.current].Command {
+			m.steps[m.current].Command = command
+			m.steps[m.current].Edited = true
+		}
+		m.editing = false
+		m.input = nil
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyCtrlU:
+		m.input = nil
+	case tea.KeySpace:
+		m.input = append(m.input, ' ')
+	case tea.KeyRunes:
+		m.input = append(m.input, msg.Runes...)
+	}
+	return m, nil
+}
+
+// run hands the terminal to the current step until it exits.
+func (m PlanModel) run() (tea.Model, tea.Cmd) {
+	command := m.steps[m.current].Command
+	m.steps[m.current].Started = time.Now()
+	m.running = true
+	return m, tea.Sequence(
+		tea.Println(colors.RunningStyle.Render(fmt.Sprintf("Running `%s`", command))),
+		tea.Exec(&execStep{run: m.runStep, command: command}, func(err error) tea.Msg {
+			return stepDoneMsg{err: err}
+		}),
+	)
+}
+
+// advance moves to the next step, running it straight away during run-all
+// if it is marked safe.
+func (m PlanModel) advance() (tea.Model, tea.Cmd) {
+	m.current++
+	if m.current >= len(m.steps) {
+		m.done = true
+		return m, tea.Quit
+	}
+	if m.runAll && m.steps[m.current].Safe {
+		return m.run()
+	}
+	return m, nil
+}
+
+// stop ends the plan, leaving the remaining steps not run.
+func (m PlanModel) stop(line string) (tea.Model, tea.Cmd) {
+	for i := m.current; i < len(m.steps); i++ {
+		if m.steps[i].Status == "" {
+			m.steps[i].Status = history.StepNotRun
+		}
+	}
+	m.done = true
+	return m, tea.Sequence(tea.Println(line), tea.Quit)
+}
+
+// Steps returns the plan's steps with their outcomes.
+func (m PlanModel) Steps() []PlanStep {
+	return m.steps
+}
+
+// Ran reports whether any step was run.
+func (m PlanModel) Ran() bool {
+	for _, step := range m.steps {
+		if step.Status == history.StepSucceeded || step.Status == history.StepFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func (m PlanModel) View() string {
+	if m.running || m.done {
+		return ""
+	}
+
+	step := m.steps[m.current]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n",
+		colors.PromptStyle.Render(fmt.Sprintf("Step %d/%d:", m.current+1, len(m.steps))),
+		colors.EntryStyle.Render(step.Explanation))
+
+	if m.editing {
+		fmt.Fprintf(&b, "%s%s█\n", colors.PromptStyle.Render("Edit: "), string(m.input))
+		b.WriteString(colors.TimeStyle.Render("Enter save • Esc cancel • Ctrl+U clear"))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Command: %s\n", colors.CommandStyle.Render(step.Command))
+	if m.runAll && !step.Safe {
+		b.WriteString(colors.SettingsWarningStyle.Render("This step is not marked safe, so run-all paused here."))
+		b.WriteString("\n")
+	}
+	b.WriteString(colors.TimeStyle.Render("y run • e edit • s skip • a run all • q quit"))
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/history"
+)
+
+// Price is what a model charges in USD per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// DefaultPrices are the published paid-tier prices of the built-in models.
+// The pricing key in config.yaml overrides or extends them.
+var DefaultPrices = map[string]Price{
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00},
+}
+
+// Lookup returns the price of model, preferring overrides over the defaults.
+// Versioned names such as gemini-2.0-flash-001 fall back to the longest
+// priced prefix.
+func Lookup(model string, overrides map[string]Price) (Price, bool) {
+	for _, prices := range []map[string]Price{overrides, DefaultPrices} {
+		if price, ok := prices[model]; ok {
+			return price, true
+		}
+	}
+
+	best := ""
+	var price Price
+	for _, prices := range []map[string]Price{overrides, DefaultPrices} {
+		for name, p := range prices {
+			if len(name) > len(best) && strings.HasPrefix(model, name+"-") {
+				best, price = name, p
+			}
+		}
+	}
+	return price, best != ""
+}
+
+// Cost returns the cost in USD of the given token counts.
+func (p Price) Cost(promptTokens, responseTokens int64) float64 {
+	return (float64(promptTokens)*p.Input + float64(responseTokens)*p.Output) / 1e6
+}
+
+// Totals adds up a set of usage records. Unpriced counts the records whose
+// model has no known price and so are missing from Cost.
+type Totals struct {
+	Requests       int     `json:"requests"`
+	PromptTokens   int64   `json:"prompt_tokens"`
+	ResponseTokens int64   `json:"response_tokens"`
+	Cost           float64 `json:"cost_usd"`
+	Unpriced       int     `json:"unpriced,omitempty"`
+}
+
+// Group is the totals of the records sharing a day, model or mode.
+type Group struct {
+	Key string `json:"key"`
+	Totals
+}
+
+// Report breaks usage down by day, model and mode.
+type Report struct {
+	Total   Totals  `json:"total"`
+	ByDay   []Group `json:"by_day"`
+	ByModel []Group `json:"by_model"`
+	ByMode  []Group `json:"by_mode"`
+}
+
+// Summarize builds a report from records, pricing them with overrides and
+// the default prices. Days are in local time, most recent first; models and
+// modes are ordered by cost.
+func Summarize(records []history.UsageRecord, overrides map[string]Price) Report {
+	var report Report
+	days := map[string]*Totals{}
+	models := map[string]*Totals{}
+	modes := map[string]*Totals{}
+
+	for _, record := range records {
+		price, known := Lookup(record.Model, overrides)
+		report.Total.add(record, price, known)
+		group(days, record.Time.Local().Format(time.DateOnly)).add(record, price, known)
+		group(models, record.Model).add(record, price, known)
+		group(modes, record.Mode).add(record, price, known)
+	}
+
+	report.ByDay = sorted(days, func(a, b Group) bool { return a.Key > b.Key })
+	byCost := func(a, b Group) bool {
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		return a.Key < b.Key
+	}
+	report.ByModel = sorted(models, byCost)
+	report.ByMode = sorted(modes, byCost)
+
+	return report
+}
+
+// MonthStart returns midnight on the first day of the month containing t.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func (t *Totals) add(record history.UsageRecord, price Price, known bool) {
+	t.Requests++
+	t.PromptTokens += int64(record.PromptTokens)
+	t.ResponseTokens += int64(record.ResponseTokens)
+	if !known {
+		t.Unpriced++
+		return
+	}
+	t.Cost += price.Cost(int64(record.PromptTokens), int64(record.ResponseTokens))
+}
+
+func group(groups map[string]*Totals, key string) *Totals {
+	if key == "" {
+		key = "unknown"
+	}
+	if groups[key] == nil {
+		groups[key] = &Totals{}
+	}
+	return groups[key]
+}
+
+func sorted(groups map[string]*Totals, less func(a, b Group) bool) []Group {
+	result := make([]Group, 0, len(groups))
+	for key, totals := range groups {
+		result = append(result, Group{Key: key, Totals: *totals})
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	return result
+}
@@ -0,0 +1,37 @@
+// Package ted embeds ted's command generation in other Go programs.
+//
+// A Client uses the same configuration as the ted command line tool: the
+// model, API key, prompt templates, few-shot examples, agent tools, response
+// cache and history in ~/.ted. Options override individual settings:
+//
+//	client, err := ted.New(ted.WithModel("gemini-2.5-flash"))
+//	if err != nil {
+//		return err
+//	}
+//	suggestion, err := client.Suggest(ctx, "find files larger than 100MB")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(suggestion.Command)
+//
+// Suggest, Ask and Explain only generate text; nothing in this package runs
+// a command. Every request is logged for 'ted usage'.
+//
+// # Compatibility
+//
+// The module path is github.com/jadenpxrk/ted. Within a major version of the
+// module, the exported identifiers of this package are stable: functions,
+// methods and options are not removed or changed in incompatible ways, and
+// exported struct fields and their JSON names are kept. New options,
+// methods, fields and error values may be added, so don't compare structs
+// with == or use unkeyed struct literals. The wording of error messages may
+// change; match errors with errors.Is and the Err values below. Everything
+// under internal/ may change at any time.
+//
+// New reads ~/.ted/config.yaml with viper's global instance and creates the
+// file if it is missing. Programs that use the global viper instance for
+// their own configuration will see ted's keys and defaults in it. Calls to
+// New are serialized with each other, but not with the program's own use of
+// the global instance, so don't call New while another goroutine reads or
+// changes it.
+package ted
@@ -0,0 +1,116 @@
+package ted
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/history"
+)
+
+// HistoryEntry is a command ted generated and the user ran, or a plan or
+// script. Mode is the ted command that produced it.
+type HistoryEntry struct {
+	ID             uint64    `json:"id"`
+	Timestamp      time.Time `json:"timestamp"`
+	Mode           string    `json:"mode"`
+	Query          string    `json:"query"`
+	Response       string    `json:"response"`
+	Selected       *string   `json:"selected,omitempty"`
+	Steps          []Step    `json:"steps,omitempty"`
+	Script         string    `json:"script,omitempty"`
+	Model          string    `json:"model,omitempty"`
+	PromptTokens   int32     `json:"prompt_tokens"`
+	ResponseTokens int32     `json:"response_tokens"`
+	Run            *Run      `json:"run,omitempty"`
+}
+
+// Run is how the selected command of an entry ended when ted ran it. Output
+// is the end of its combined output, if capture_limit is set.
+type Run struct {
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration_ns"`
+	TimedOut  bool          `json:"timed_out,omitempty"`
+	Output    string        `json:"output,omitempty"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+// Step is one command of a plan and how it ended.
+type Step struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+	Edited      bool   `json:"edited"`
+	Status      string `json:"status"`
+	ExitCode    int    `json:"exit_code"`
+}
+
+// History returns ted's history, most recent first.
+func (c *Client) History(ctx context.Context) ([]HistoryEntry, error) {
+	return c.SearchHistory(ctx, "", 0)
+}
+
+// SearchHistory returns up to limit history entries whose query, response or
+// command contains query, ignoring case, most recent first. An empty query
+// matches every entry and a limit of 0 means no limit.
+func (c *Client) SearchHistory(ctx context.Context, query string, limit int) ([]HistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading history: %w", err)
+	}
+	defer hist.Close()
+
+	entries, err := hist.GetEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	result := []HistoryEntry{}
+	for _, entry := range entries {
+		if limit > 0 && len(result) == limit {
+			break
+		}
+
+		text := entry.Query + "\n" + entry.Response
+		if entry.Selected != nil {
+			text += "\n" + *entry.Selected
+		}
+		for _, step := range entry.Steps {
+			text += "\n" + step.Command
+		}
+		if query != "" && !strings.Contains(strings.ToLower(text), query) {
+			continue
+		}
+
+		result = append(result, newHistoryEntry(entry))
+	}
+	return result, nil
+}
+
+func newHistoryEntry(entry history.Entry) HistoryEntry {
+	result := HistoryEntry{
+		ID:             entry.ID,
+		Timestamp:      entry.Timestamp,
+		Mode:           entry.Command,
+		Query:          entry.Query,
+		Response:       entry.Response,
+		Selected:       entry.Selected,
+		Script:         entry.Script,
+		Model:          entry.Tokens.Model,
+		PromptTokens:   entry.Tokens.PromptTokens,
+		ResponseTokens: entry.Tokens.ResponseTokens,
+	}
+	for _, step := range entry.Steps {
+		result.Steps = append(result.Steps, Step(step))
+	}
+	if entry.Run != nil {
+		run := Run(*entry.Run)
+		result.Run = &run
+	}
+	return result
+}
@@ -0,0 +1,353 @@
+package ted
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/config"
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/history"
+	"github.com/jadenpxrk/ted/internal/prompts"
+	"github.com/jadenpxrk/ted/internal/provider"
+	"github.com/jadenpxrk/ted/internal/shell"
+	"github.com/jadenpxrk/ted/internal/tools"
+)
+
+// Errors returned by Client methods, wrapped with more detail.
+var (
+	ErrNoAPIKey      = provider.ErrNoAPIKey
+	ErrRateLimited   = gemini.ErrRateLimited
+	ErrInvalidAPIKey = gemini.ErrInvalidAPIKey
+	ErrBlocked       = gemini.ErrBlocked
+	ErrTimeout       = gemini.ErrTimeout
+)
+
+// Client generates commands with ted's configuration. Its methods are safe
+// for concurrent use, and so is New; see the package documentation for how
+// New shares viper's global instance.
+type Client struct {
+	cfg   *config.Config
+	shell string
+	cache bool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey sets the Gemini API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.cfg.GeminiAPIKey = key }
+}
+
+// WithModel sets the model, e.g. "gemini-2.0-flash".
+func WithModel(name string) Option {
+	return func(c *Client) { c.cfg.Model = name }
+}
+
+// WithTemperature sets the sampling temperature, from 0.0 to 1.0.
+func WithTemperature(temperature float32) Option {
+	return func(c *Client) { c.cfg.Temperature = temperature }
+}
+
+// WithShell sets the shell commands are written for, e.g. "zsh". By default
+// it is the configured shell or $SHELL.
+func WithShell(name string) Option {
+	return func(c *Client) { c.shell = name }
+}
+
+// WithTools sets the read-only tools Suggest lets the model call. Calling it
+// without names turns tools off.
+func WithTools(names ...string) Option {
+	return func(c *Client) { c.cfg.Tools = names }
+}
+
+// WithCache turns the response cache on or off. It is on by default unless
+// cache_ttl is 0.
+func WithCache(enabled bool) Option {
+	return func(c *Client) { c.cache = enabled }
+}
+
+// WithTimeout bounds each request to the provider; 0 means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.cfg.RequestTimeout = timeout }
+}
+
+// WithMaxRetries sets how often a rate-limited or failed request is retried.
+func WithMaxRetries(retries int) Option {
+	return func(c *Client) { c.cfg.MaxRetries = retries }
+}
+
+// New creates a client from ~/.ted/config.yaml and opts.
+func New(opts ...Option) (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+
+	c := &Client{cfg: cfg, cache: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.cfg.GeminiAPIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	for _, name := range c.cfg.Tools {
+		if !slices.Contains(tools.Names, name) {
+			return nil, fmt.Errorf("unknown tool %q", name)
+		}
+	}
+	if c.shell == "" {
+		// A missing configured shell falls back to sh, which Resolve reports
+		// as an error alongside a usable executor.
+		sh, _ := shell.Resolve(cfg.Shell, cfg.ShellRC)
+		c.shell = sh.Name
+	}
+
+	return c, nil
+}
+
+// Model returns the name of the model the client uses.
+func (c *Client) Model() string {
+	return c.cfg.Model
+}
+
+// CallOption configures a single Suggest, Ask or Explain call.
+type CallOption func(*call)
+
+type call struct {
+	input   string
+	count   int
+	refresh bool
+	noTools bool
+	onTool  func(ToolCall)
+}
+
+// WithInput adds text the command should work with, such as an error message
+// or log excerpt, like piping it into ted.
+func WithInput(text string) CallOption {
+	return func(c *call) { c.input = text }
+}
+
+// WithCount sets how many options Ask returns, from 1 to 10. By default it is
+// the configured ask_count.
+func WithCount(n int) CallOption {
+	return func(c *call) { c.count = n }
+}
+
+// RefreshCache ignores a cached answer and caches the new one.
+func RefreshCache() CallOption {
+	return func(c *call) { c.refresh = true }
+}
+
+// WithoutTools stops the model calling tools during this Suggest call.
+func WithoutTools() CallOption {
+	return func(c *call) { c.noTools = true }
+}
+
+// OnToolCall calls fn before each tool the model calls during Suggest.
+func OnToolCall(fn func(ToolCall)) CallOption {
+	return func(c *call) { c.onTool = fn }
+}
+
+// Suggest generates a single command that does what query describes.
+func (c *Client) Suggest(ctx context.Context, query string, opts ...CallOption) (*Suggestion, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	options := newCall(opts)
+
+	data := c.data(query, options)
+	if !options.noTools {
+		data.Tools = c.cfg.Tools
+	}
+
+	client, prompt, err := c.prepare("agent", prompts.Agent, data)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	req, err := provider.CacheRequest(c.cfg, "agent", data, prompts.Agent)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Tools) > 0 {
+		// Tool output depends on where ted runs.
+		req.Context = append(req.Context, data.Cwd, strings.Join(data.Tools, ","))
+	}
+
+	start := time.Now()
+	response, hit, err := provider.Cached(c.cfg, req, c.policy(options), func() (*gemini.AgentResponse, error) {
+		if len(data.Tools) == 0 {
+			return client.GenerateAgentCommand(ctx, prompt)
+		}
+		return client.GenerateAgentCommandWithTools(ctx, prompt, tools.Specs(data.Tools), func(ctx context.Context, tc gemini.ToolCall) string {
+			if options.onTool != nil {
+				options.onTool(ToolCall(tc))
+			}
+			return tools.Run(ctx, tc, data.Tools, c.cfg.ToolOutputLimit)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating command: %w", err)
+	}
+
+	suggestion := &Suggestion{
+		Command:     response.Command,
+		Explanation: response.Explanation,
+		Meta:        c.meta(start, response.Usage, hit),
+	}
+	for _, tc := range response.ToolCalls {
+		suggestion.ToolCalls = append(suggestion.ToolCalls, ToolCall(tc))
+	}
+	return suggestion, nil
+}
+
+// Ask generates several alternative commands that answer question.
+func (c *Client) Ask(ctx context.Context, question string, opts ...CallOption) (*Suggestions, error) {
+	if strings.TrimSpace(question) == "" {
+		return nil, fmt.Errorf("question is empty")
+	}
+	options := newCall(opts)
+
+	count := options.count
+	if count == 0 {
+		count = c.cfg.AskCount
+	}
+	if count < 1 || count > config.MaxAskCount {
+		return nil, fmt.Errorf("number of suggestions must be between 1 and %d", config.MaxAskCount)
+	}
+
+	data := c.data(question, options)
+	data.NumOptions = count
+
+	client, prompt, err := c.prepare("ask", prompts.Ask, data)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	req, err := provider.CacheRequest(c.cfg, "ask", data, prompts.Ask)
+	if err != nil {
+		return nil, err
+	}
+	req.Context = append(req.Context, strconv.Itoa(count))
+
+	start := time.Now()
+	response, hit, err := provider.Cached(c.cfg, req, c.policy(options), func() (*gemini.AskResponse, error) {
+		return client.GenerateAskCommands(ctx, prompt, count)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating commands: %w", err)
+	}
+
+	suggestions := &Suggestions{Meta: c.meta(start, response.Usage, hit)}
+	for _, option := range response.Commands[:min(count, len(response.Commands))] {
+		suggestions.Commands = append(suggestions.Commands, Candidate(option))
+	}
+	return suggestions, nil
+}
+
+// Explain breaks command down stage by stage and argument by argument, with
+// the risk of each part.
+func (c *Client) Explain(ctx context.Context, command string, opts ...CallOption) (*Explanation, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+	options := newCall(opts)
+
+	data := c.data(command, options)
+
+	client, prompt, err := c.prepare("explain", prompts.Explain, data)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	req, err := provider.CacheRequest(c.cfg, "explain", data, prompts.Explain)
+	if err != nil {
+		return nil, err
+	}
+	// Unlike a question, a command's meaning depends on its exact case.
+	req.Context = append(req.Context, command)
+
+	start := time.Now()
+	response, hit, err := provider.Cached(c.cfg, req, c.policy(options), func() (*gemini.ExplainResponse, error) {
+		return client.ExplainCommand(ctx, prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error explaining command: %w", err)
+	}
+
+	explanation := &Explanation{
+		Summary:   response.Summary,
+		RiskLevel: response.RiskLevel,
+		Meta:      c.meta(start, response.Usage, hit),
+	}
+	for _, stage := range response.Stages {
+		s := Stage{
+			Command:     stage.Command,
+			Explanation: stage.Explanation,
+			RiskLevel:   stage.RiskLevel,
+			RiskReason:  stage.RiskReason,
+		}
+		for _, arg := range stage.Arguments {
+			s.Arguments = append(s.Arguments, Argument(arg))
+		}
+		explanation.Stages = append(explanation.Stages, s)
+	}
+	return explanation, nil
+}
+
+func newCall(opts []CallOption) call {
+	var c call
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c *Client) data(query string, options call) prompts.Data {
+	data := prompts.NewData(query, c.shell)
+	data.Input = options.input
+	return data
+}
+
+// prepare creates a model client for mode and renders the named template.
+func (c *Client) prepare(mode, template string, data prompts.Data) (*gemini.Client, string, error) {
+	data.Mode = mode
+	client, err := provider.NewClient(c.cfg, mode, data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prompt, err := prompts.Render(template, c.cfg.Prompts, data)
+	if err != nil {
+		client.Close()
+		return nil, "", err
+	}
+	return client, prompt, nil
+}
+
+func (c *Client) policy(options call) provider.CachePolicy {
+	return provider.CachePolicy{Skip: !c.cache, Refresh: options.refresh}
+}
+
+func (c *Client) meta(start time.Time, usage gemini.Usage, hit *history.CacheEntry) Meta {
+	meta := Meta{
+		Model:   c.cfg.Model,
+		Latency: time.Since(start),
+		Usage:   Usage(usage),
+	}
+	if hit != nil {
+		meta.Cached = true
+		meta.CachedAt = hit.Created
+	}
+	return meta
+}
@@ -0,0 +1,98 @@
+package ted
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jadenpxrk/ted/internal/gemini"
+	"github.com/jadenpxrk/ted/internal/tools"
+)
+
+// Risk levels reported for commands and their parts.
+const (
+	RiskLow    = gemini.RiskLow
+	RiskMedium = gemini.RiskMedium
+	RiskHigh   = gemini.RiskHigh
+)
+
+// Suggestion is a single command returned by Suggest.
+type Suggestion struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+
+	// ToolCalls lists the tools the model called before answering.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	Meta Meta `json:"-"`
+}
+
+// Suggestions are the alternative commands returned by Ask.
+type Suggestions struct {
+	Commands []Candidate `json:"commands"`
+	Meta     Meta        `json:"-"`
+}
+
+// Candidate is one of the commands returned by Ask.
+type Candidate struct {
+	Command       string   `json:"command"`
+	Description   string   `json:"description"`
+	RiskLevel     string   `json:"risk_level"`
+	RequiredTools []string `json:"required_tools"`
+	ModifiesState bool     `json:"modifies_state"`
+}
+
+// Explanation is the breakdown of a command returned by Explain.
+type Explanation struct {
+	Summary   string  `json:"summary"`
+	RiskLevel string  `json:"risk_level"`
+	Stages    []Stage `json:"stages"`
+	Meta      Meta    `json:"-"`
+}
+
+// Stage is one stage of a pipeline or command list.
+type Stage struct {
+	Command     string     `json:"command"`
+	Explanation string     `json:"explanation"`
+	RiskLevel   string     `json:"risk_level"`
+	RiskReason  string     `json:"risk_reason"`
+	Arguments   []Argument `json:"arguments"`
+}
+
+// Argument is a flag, argument or redirection within a stage.
+type Argument struct {
+	Text        string `json:"text"`
+	Explanation string `json:"explanation"`
+	RiskLevel   string `json:"risk_level"`
+}
+
+// ToolCall is a read-only tool the model called, with its arguments.
+type ToolCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+// String describes the call, e.g. read_file(path=Makefile, lines=40).
+func (tc ToolCall) String() string {
+	return tools.Describe(gemini.ToolCall(tc))
+}
+
+// Meta describes how a response was produced. A cached response used no
+// tokens, so its Usage is zero.
+type Meta struct {
+	Model    string
+	Latency  time.Duration
+	Usage    Usage
+	Cached   bool
+	CachedAt time.Time
+}
+
+// Usage holds the token counts reported for a request.
+type Usage struct {
+	PromptTokens   int32 `json:"prompt_tokens"`
+	ResponseTokens int32 `json:"response_tokens"`
+	TotalTokens    int32 `json:"total_tokens"`
+}
+
+func (u Usage) String() string {
+	return fmt.Sprintf("%d tokens in, %d out", u.PromptTokens, u.ResponseTokens)
+}